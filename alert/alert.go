@@ -0,0 +1,91 @@
+// Package alert runs a user command once when a budget threshold is crossed.
+// Fired alerts are remembered on disk, so the command runs once per window
+// even though the statusline is rendered by many short-lived processes.
+package alert
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strconv"
+	"time"
+
+	"github.com/iskorotkov/cc-statusline/dirs"
+	"github.com/iskorotkov/cc-statusline/shell"
+)
+
+// retention is the time after which fired alerts are forgotten. It is longer
+// than the longest window, so an alert never fires twice for one window.
+const retention = 62 * 24 * time.Hour
+
+// Alert is a budget threshold crossed in a usage window.
+type Alert struct {
+	// Window is the name of the window, such as "day".
+	Window string
+	// Key identifies the window instance, such as its start date.
+	Key string
+	// Root is the label of the Claude config dir the cost is limited to,
+	// empty for all of them.
+	Root string
+	// Level is "warn" or "alert".
+	Level   string
+	Cost    float64
+	Budget  float64
+	Percent float64
+}
+
+// Fire runs command in the background with the alert in CC_BUDGET_*
+// environment variables, unless it already ran for the same window, key,
+// root and level. It reports whether the command was started.
+func Fire(command string, a Alert) (bool, error) {
+	dir, err := dirs.Cache()
+	if err != nil {
+		return false, err
+	}
+	dir = filepath.Join(dir, "alerts")
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return false, fmt.Errorf("create alerts dir: %w", err)
+	}
+	hash := sha256.Sum256([]byte(a.Window + "\x00" + a.Key + "\x00" + a.Root + "\x00" + a.Level))
+	path := filepath.Join(dir, a.Window+"-"+a.Level+"-"+hex.EncodeToString(hash[:8]))
+	f, err := os.OpenFile(path, os.O_CREATE|os.O_EXCL|os.O_WRONLY, 0o644)
+	if errors.Is(err, fs.ErrExist) {
+		return false, nil
+	}
+	if err != nil {
+		return false, fmt.Errorf("create alert marker: %w", err)
+	}
+	_ = f.Close()
+	prune(dir)
+	cmd := shell.Command(command)
+	cmd.Env = append(os.Environ(),
+		"CC_BUDGET_WINDOW="+a.Window,
+		"CC_BUDGET_LEVEL="+a.Level,
+		"CC_BUDGET_ROOT="+a.Root,
+		"CC_BUDGET_COST="+strconv.FormatFloat(a.Cost, 'f', 2, 64),
+		"CC_BUDGET_LIMIT="+strconv.FormatFloat(a.Budget, 'f', 2, 64),
+		"CC_BUDGET_PERCENT="+strconv.FormatFloat(a.Percent, 'f', 0, 64),
+	)
+	if err := shell.Start(cmd); err != nil {
+		return false, fmt.Errorf("start alert command: %w", err)
+	}
+	return true, nil
+}
+
+// prune removes markers of alerts fired longer ago than retention.
+func prune(dir string) {
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return
+	}
+	for _, e := range entries {
+		info, err := e.Info()
+		if err == nil && time.Since(info.ModTime()) > retention {
+			_ = os.Remove(filepath.Join(dir, e.Name()))
+		}
+	}
+}
@@ -0,0 +1,59 @@
+package alert_test
+
+import (
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/iskorotkov/cc-statusline/alert"
+)
+
+func TestFire(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("alert command uses sh")
+	}
+	dir := t.TempDir()
+	t.Setenv("XDG_CACHE_HOME", dir)
+	out := filepath.Join(dir, "out")
+	command := `echo "$CC_BUDGET_WINDOW $CC_BUDGET_LEVEL $CC_BUDGET_COST/$CC_BUDGET_LIMIT" >> ` + out
+
+	day := alert.Alert{Window: "day", Key: "2025-01-01", Level: "warn", Cost: 16, Budget: 20, Percent: 80}
+	for i, want := range []bool{true, false} {
+		fired, err := alert.Fire(command, day)
+		if err != nil {
+			t.Fatalf("Fire() error: %v", err)
+		}
+		if fired != want {
+			t.Errorf("Fire() call %d = %v, want %v", i+1, fired, want)
+		}
+	}
+	next := day
+	next.Key = "2025-01-02"
+	if fired, err := alert.Fire(command, next); err != nil || !fired {
+		t.Errorf("Fire() for the next day = %v, %v, want true", fired, err)
+	}
+	work := day
+	work.Root = "work"
+	if fired, err := alert.Fire(command, work); err != nil || !fired {
+		t.Errorf("Fire() for another root = %v, %v, want true", fired, err)
+	}
+
+	want := "day warn 16.00/20.00\nday warn 16.00/20.00\nday warn 16.00/20.00\n"
+	deadline := time.Now().Add(5 * time.Second)
+	for {
+		data, _ := os.ReadFile(out)
+		if string(data) == want {
+			break
+		}
+		if time.Now().After(deadline) {
+			t.Fatalf("command output = %q, want %q", data, want)
+		}
+		if strings.Count(string(data), "\n") > 3 {
+			t.Fatalf("command ran too many times: %q", data)
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+}
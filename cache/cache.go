@@ -0,0 +1,200 @@
+package cache
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"time"
+
+	"github.com/iskorotkov/cc-statusline/dirs"
+	"github.com/iskorotkov/cc-statusline/shell"
+)
+
+// Command is the subcommand that refreshes a source in a background process.
+const Command = "refresh"
+
+// lockTTL is the time after which a refresh is considered dead and another
+// one may be started.
+const lockTTL = time.Minute
+
+// Source is a value cached on disk under a key, such as a repo and branch.
+type Source[T any] struct {
+	Name  string
+	Key   func(ctx context.Context) (string, error)
+	Fetch func(ctx context.Context) (T, error)
+}
+
+type entry struct {
+	Time  time.Time       `json:"time"`
+	Value json.RawMessage `json:"value"`
+	Error string          `json:"error,omitempty"`
+}
+
+// Get returns the cached value. A value older than ttl is still returned
+// immediately, and a background process is started to refresh it. A missing
+// value is fetched synchronously. Caching is disabled when ttl is 0.
+func (s *Source[T]) Get(ctx context.Context, ttl time.Duration) (T, error) {
+	if ttl <= 0 {
+		return s.Fetch(ctx)
+	}
+	path, err := s.path(ctx)
+	if err != nil {
+		return s.Fetch(ctx)
+	}
+	if e, err := read(path); err == nil {
+		if time.Since(e.Time) >= ttl {
+			spawn(s.Name, path)
+		}
+		return decode[T](e)
+	}
+	v, err := s.Fetch(ctx)
+	if ctx.Err() != nil {
+		spawn(s.Name, path)
+		return v, err
+	}
+	_ = write(path, v, err)
+	return v, err
+}
+
+// Refresh fetches the value and stores it in the cache. A failed fetch keeps
+// a cached value and only retries it once the value is stale again, so that
+// temporary failures do not hide the value.
+func (s *Source[T]) Refresh(ctx context.Context) error {
+	path, err := s.path(ctx)
+	if err != nil {
+		return err
+	}
+	defer func() {
+		_ = os.Remove(path + ".lock")
+	}()
+	v, err := s.Fetch(ctx)
+	if ctx.Err() != nil {
+		return ctx.Err()
+	}
+	if e, readErr := read(path); err != nil && readErr == nil && e.Error == "" {
+		e.Time = time.Now()
+		return save(path, e)
+	}
+	return write(path, v, err)
+}
+
+func (s *Source[T]) path(ctx context.Context) (string, error) {
+	key, err := s.Key(ctx)
+	if err != nil {
+		return "", fmt.Errorf("get cache key: %w", err)
+	}
+	dir, err := dirs.Cache()
+	if err != nil {
+		return "", err
+	}
+	hash := sha256.Sum256([]byte(s.Name + "\x00" + key))
+	return filepath.Join(dir, "cache", s.Name+"-"+hex.EncodeToString(hash[:8])+".json"), nil
+}
+
+func read(path string) (entry, error) {
+	var e entry
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return e, err
+	}
+	if err := json.Unmarshal(data, &e); err != nil {
+		return e, fmt.Errorf("decode cache entry: %w", err)
+	}
+	return e, nil
+}
+
+func decode[T any](e entry) (T, error) {
+	var v T
+	if e.Error != "" {
+		return v, errors.New(e.Error)
+	}
+	if err := json.Unmarshal(e.Value, &v); err != nil {
+		return v, fmt.Errorf("decode cached value: %w", err)
+	}
+	return v, nil
+}
+
+// write stores the value, or the error if fetching failed, so that failures
+// such as a missing PR are not retried on every run.
+func write[T any](path string, v T, fetchErr error) error {
+	e := entry{Time: time.Now()}
+	if fetchErr != nil {
+		e.Error = fetchErr.Error()
+	} else {
+		value, err := json.Marshal(v)
+		if err != nil {
+			return fmt.Errorf("encode value: %w", err)
+		}
+		e.Value = value
+	}
+	return save(path, e)
+}
+
+// save writes the entry to path atomically.
+func save(path string, e entry) error {
+	data, err := json.Marshal(e)
+	if err != nil {
+		return fmt.Errorf("encode cache entry: %w", err)
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		return fmt.Errorf("create cache dir: %w", err)
+	}
+	tmp, err := os.CreateTemp(filepath.Dir(path), filepath.Base(path)+".*")
+	if err != nil {
+		return fmt.Errorf("create cache entry: %w", err)
+	}
+	defer func() {
+		_ = os.Remove(tmp.Name())
+	}()
+	if _, err := tmp.Write(data); err != nil {
+		_ = tmp.Close()
+		return fmt.Errorf("write cache entry: %w", err)
+	}
+	if err := tmp.Close(); err != nil {
+		return fmt.Errorf("close cache entry: %w", err)
+	}
+	return os.Rename(tmp.Name(), path)
+}
+
+// spawn starts a detached process refreshing the source unless another one
+// is already running for the same entry.
+func spawn(name, path string) {
+	if !lock(path + ".lock") {
+		return
+	}
+	exe, err := os.Executable()
+	if err != nil {
+		_ = os.Remove(path + ".lock")
+		return
+	}
+	if err := shell.Start(exec.Command(exe, Command, name)); err != nil {
+		_ = os.Remove(path + ".lock")
+	}
+}
+
+func lock(path string) bool {
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		return false
+	}
+	f, err := os.OpenFile(path, os.O_CREATE|os.O_EXCL|os.O_WRONLY, 0o644)
+	if errors.Is(err, fs.ErrExist) {
+		info, statErr := os.Stat(path)
+		if statErr != nil || time.Since(info.ModTime()) < lockTTL {
+			return false
+		}
+		_ = os.Remove(path)
+		f, err = os.OpenFile(path, os.O_CREATE|os.O_EXCL|os.O_WRONLY, 0o644)
+	}
+	if err != nil {
+		return false
+	}
+	_ = f.Close()
+	return true
+}
@@ -0,0 +1,142 @@
+package cache_test
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/iskorotkov/cc-statusline/cache"
+)
+
+// TestMain exits refresh processes spawned by stale cache entries, which run
+// the test binary.
+func TestMain(m *testing.M) {
+	if len(os.Args) > 1 && os.Args[1] == cache.Command {
+		os.Exit(0)
+	}
+	os.Exit(m.Run())
+}
+
+func counter(value string, err error) (*cache.Source[string], *int) {
+	var calls int
+	return &cache.Source[string]{
+		Name: "test",
+		Key: func(ctx context.Context) (string, error) {
+			return "key", nil
+		},
+		Fetch: func(ctx context.Context) (string, error) {
+			calls++
+			return value, err
+		},
+	}, &calls
+}
+
+func TestGet(t *testing.T) {
+	t.Setenv("XDG_CACHE_HOME", t.TempDir())
+	s, calls := counter("value", nil)
+	for range 3 {
+		v, err := s.Get(context.Background(), time.Hour)
+		if err != nil {
+			t.Fatalf("Get() error: %v", err)
+		}
+		if v != "value" {
+			t.Errorf("Get() = %q, want %q", v, "value")
+		}
+	}
+	if *calls != 1 {
+		t.Errorf("fetched %d times, want 1", *calls)
+	}
+}
+
+func TestGetError(t *testing.T) {
+	t.Setenv("XDG_CACHE_HOME", t.TempDir())
+	s, calls := counter("", errors.New("no pull requests found"))
+	for range 2 {
+		if _, err := s.Get(context.Background(), time.Hour); err == nil || err.Error() != "no pull requests found" {
+			t.Errorf("Get() error = %v, want cached error", err)
+		}
+	}
+	if *calls != 1 {
+		t.Errorf("fetched %d times, want 1", *calls)
+	}
+}
+
+func TestGetDisabled(t *testing.T) {
+	t.Setenv("XDG_CACHE_HOME", t.TempDir())
+	s, calls := counter("value", nil)
+	for range 2 {
+		if _, err := s.Get(context.Background(), 0); err != nil {
+			t.Fatalf("Get() error: %v", err)
+		}
+	}
+	if *calls != 2 {
+		t.Errorf("fetched %d times, want 2", *calls)
+	}
+}
+
+func TestRefresh(t *testing.T) {
+	t.Setenv("XDG_CACHE_HOME", t.TempDir())
+	s, calls := counter("value", nil)
+	if err := s.Refresh(context.Background()); err != nil {
+		t.Fatalf("Refresh() error: %v", err)
+	}
+	if _, err := s.Get(context.Background(), time.Hour); err != nil {
+		t.Fatalf("Get() error: %v", err)
+	}
+	if *calls != 1 {
+		t.Errorf("fetched %d times, want 1", *calls)
+	}
+}
+
+func TestGetStale(t *testing.T) {
+	dir := t.TempDir()
+	t.Setenv("XDG_CACHE_HOME", dir)
+	s, calls := counter("value", nil)
+	if _, err := s.Get(context.Background(), time.Hour); err != nil {
+		t.Fatalf("Get() error: %v", err)
+	}
+	v, err := s.Get(context.Background(), time.Nanosecond)
+	if err != nil {
+		t.Fatalf("Get() of stale value error: %v", err)
+	}
+	if v != "value" {
+		t.Errorf("Get() of stale value = %q, want %q", v, "value")
+	}
+	if *calls != 1 {
+		t.Errorf("fetched %d times, want 1", *calls)
+	}
+	locks, err := filepath.Glob(filepath.Join(dir, "cc-statusline", "cache", "test-*.json.lock"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(locks) != 1 {
+		t.Errorf("got %d refresh locks, want 1", len(locks))
+	}
+}
+
+func TestRefreshError(t *testing.T) {
+	t.Setenv("XDG_CACHE_HOME", t.TempDir())
+	good, _ := counter("value", nil)
+	if err := good.Refresh(context.Background()); err != nil {
+		t.Fatalf("Refresh() error: %v", err)
+	}
+	failing, _ := counter("", errors.New("offline"))
+	if err := failing.Refresh(context.Background()); err != nil {
+		t.Fatalf("Refresh() with failed fetch error: %v", err)
+	}
+	v, err := failing.Get(context.Background(), time.Hour)
+	if err != nil || v != "value" {
+		t.Errorf("Get() after failed refresh = %q, %v, want %q", v, err, "value")
+	}
+
+	t.Setenv("XDG_CACHE_HOME", t.TempDir())
+	if err := failing.Refresh(context.Background()); err != nil {
+		t.Fatalf("Refresh() with failed fetch error: %v", err)
+	}
+	if _, err := failing.Get(context.Background(), time.Hour); err == nil || err.Error() != "offline" {
+		t.Errorf("Get() after failed refresh of missing value error = %v, want cached error", err)
+	}
+}
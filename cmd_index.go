@@ -0,0 +1,34 @@
+package main
+
+import (
+	"fmt"
+	"io"
+	"maps"
+	"slices"
+
+	"github.com/iskorotkov/cc-statusline/transcript"
+)
+
+// indexCommand manages the transcript index.
+func indexCommand(w io.Writer, args []string) error {
+	if len(args) != 1 || args[0] != "rebuild" {
+		return fmt.Errorf("usage: cc-statusline index rebuild")
+	}
+	if err := transcript.RebuildIndex(); err != nil {
+		return err
+	}
+	transcripts, err := transcript.ParseTranscripts()
+	if err != nil {
+		return err
+	}
+	var events int
+	for _, t := range transcripts {
+		events += len(t.Events)
+	}
+	_, _ = fmt.Fprintf(w, "indexed %d events in %d transcripts\n", events, len(transcripts))
+	d := transcript.Diagnose(transcripts)
+	for _, file := range slices.Sorted(maps.Keys(d.SkippedLines)) {
+		_, _ = fmt.Fprintf(w, "skipped %d invalid lines in %s\n", d.SkippedLines[file], file)
+	}
+	return nil
+}
@@ -0,0 +1,84 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"reflect"
+	"strings"
+	"time"
+
+	"github.com/iskorotkov/cc-statusline/parts"
+	"github.com/iskorotkov/cc-statusline/style"
+)
+
+const sampleHookJSON = `{
+  "session_id": "sample-session",
+  "transcript_path": "/home/user/.claude/projects/-home-user-project/sample-session.jsonl",
+  "version": "1.0.0",
+  "model": {"id": "claude-sonnet-4-20250514", "display_name": "Sonnet 4"},
+  "output_style": {"name": "default"},
+  "workspace": {"project_dir": "/home/user/project", "current_dir": "/home/user/project/src"},
+  "cost": {"total_lines_added": 150, "total_lines_removed": 75, "total_api_duration_ms": 5000, "total_cost_usd": 1.25},
+  "exceeds_200k_tokens": true
+}`
+
+// listParts prints every registered part with its params and the output it
+// renders for a sample hook in the current dir.
+func listParts(ctx context.Context, w io.Writer) error {
+	var hook parts.CCHook
+	if err := json.Unmarshal([]byte(sampleHookJSON), &hook); err != nil {
+		return fmt.Errorf("decode sample hook: %w", err)
+	}
+	for i, d := range parts.Definitions() {
+		if i > 0 {
+			_, _ = fmt.Fprintln(w)
+		}
+		_, _ = fmt.Fprintf(w, "%s\n  %s\n", style.Bold(d.Name), d.Description)
+		if fields := dataFields(d.Data); len(fields) > 0 {
+			_, _ = fmt.Fprintf(w, "  fields: %s\n", strings.Join(fields, " "))
+		}
+		for _, p := range d.Params {
+			def := p.Default
+			if d, ok := def.(time.Duration); ok {
+				def = d.String()
+			}
+			_, _ = fmt.Fprintf(w, "  %s %s = %#v  %s\n", p.Name, p.Type, def, style.Dim(p.Description))
+		}
+		_, _ = fmt.Fprintf(w, "  sample: %s\n", samplePart(ctx, d.Name, hook))
+	}
+	return nil
+}
+
+// dataFields lists the top-level fields available in a part format.
+func dataFields(data any) []string {
+	t := reflect.TypeOf(data)
+	if t == nil || t.Kind() != reflect.Struct {
+		return nil
+	}
+	fields := make([]string, 0, t.NumField())
+	for i := range t.NumField() {
+		if f := t.Field(i); f.IsExported() {
+			fields = append(fields, "."+f.Name)
+		}
+	}
+	return fields
+}
+
+func samplePart(ctx context.Context, name string, hook parts.CCHook) string {
+	ctx, cancel := context.WithTimeout(ctx, 5*time.Second)
+	defer cancel()
+	p, err := parts.New(name, nil)
+	if err != nil {
+		return style.Red(err.Error())
+	}
+	s, err := p(ctx, hook)
+	if err != nil {
+		return style.Red(err.Error())
+	}
+	if s == "" {
+		return style.Dim("(empty)")
+	}
+	return s
+}
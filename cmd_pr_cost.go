@@ -0,0 +1,85 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/iskorotkov/cc-statusline/config"
+	"github.com/iskorotkov/cc-statusline/pricing"
+	"github.com/iskorotkov/cc-statusline/report"
+	"github.com/iskorotkov/cc-statusline/shell"
+	"github.com/iskorotkov/cc-statusline/transcript"
+)
+
+// prCostCommand prints usage and cost of Claude Code sessions on a git
+// branch of the repo in the working dir, by default as Markdown for a PR
+// description.
+func prCostCommand(ctx context.Context, w io.Writer, args []string) error {
+	fs := flag.NewFlagSet("pr-cost", flag.ContinueOnError)
+	fs.SetOutput(w)
+	branch := fs.String("branch", "", "git branch, defaults to the current one")
+	by := fs.String("by", report.ByModel, "group rows by "+strings.Join(report.Groupings, ", "))
+	format := fs.String("format", report.FormatMarkdown, "output format, "+strings.Join(report.Formats, ", "))
+	if err := fs.Parse(args); errors.Is(err, flag.ErrHelp) {
+		return nil
+	} else if err != nil {
+		return err
+	}
+	if fs.NArg() > 0 {
+		return fmt.Errorf("unexpected arguments %v", fs.Args())
+	}
+
+	repo, err := shell.String(ctx, "git", "rev-parse", "--show-toplevel")
+	if err != nil {
+		return fmt.Errorf("get repo root: %w", err)
+	}
+	if *branch == "" {
+		if *branch, err = shell.String(ctx, "git", "branch", "--show-current"); err != nil {
+			return fmt.Errorf("get current branch: %w", err)
+		}
+		if *branch == "" {
+			return errors.New("HEAD is detached, pass --branch")
+		}
+	}
+
+	cfg, err := config.Load()
+	if err != nil {
+		return err
+	}
+	settings := cfg.Settings()
+	pricing.Configure(settings.Pricing)
+	roots, err := transcript.DefaultRoots()
+	if err != nil {
+		return err
+	}
+	transcripts, err := transcript.ParseRoots(append(roots, settings.Roots...))
+	if err != nil {
+		return err
+	}
+	transcripts = transcript.FilterProjectTree(transcripts, repo)
+	opts := report.Options{
+		By:        *by,
+		Location:  settings.Location,
+		WeekStart: settings.WeekStart,
+		Branch:    *branch,
+	}
+	rows, err := report.Build(transcripts, opts)
+	if err != nil {
+		return err
+	}
+	if *format == report.FormatMarkdown {
+		opts.By = report.BySession
+		sessions, err := report.Build(transcripts, opts)
+		if err != nil {
+			return err
+		}
+		total := report.Total(rows)
+		_, _ = fmt.Fprintf(w, "### Claude Code usage on `%s`\n\n", *branch)
+		_, _ = fmt.Fprintf(w, "%d sessions, %d tokens, $%.2f.\n\n", len(sessions), total.TotalTokens, total.Cost)
+	}
+	return report.Write(w, rows, *format)
+}
@@ -0,0 +1,78 @@
+package main
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"strings"
+	"time"
+
+	"github.com/iskorotkov/cc-statusline/config"
+	"github.com/iskorotkov/cc-statusline/pricing"
+	"github.com/iskorotkov/cc-statusline/report"
+	"github.com/iskorotkov/cc-statusline/transcript"
+)
+
+// reportCommand prints usage and cost of transcripts grouped by time
+// period, session, model or project.
+func reportCommand(w io.Writer, args []string) error {
+	fs := flag.NewFlagSet("report", flag.ContinueOnError)
+	fs.SetOutput(w)
+	by := fs.String("by", report.ByDay, "group rows by "+strings.Join(report.Groupings, ", "))
+	since := fs.String("since", "", "first date to include, as YYYY-MM-DD")
+	until := fs.String("until", "", "last date to include, as YYYY-MM-DD")
+	top := fs.Int("top", 0, "show only the N most expensive sessions, models or projects")
+	format := fs.String("format", report.FormatText, "output format, "+strings.Join(report.Formats, ", "))
+	if err := fs.Parse(args); errors.Is(err, flag.ErrHelp) {
+		return nil
+	} else if err != nil {
+		return err
+	}
+	if fs.NArg() > 0 {
+		return fmt.Errorf("unexpected arguments %v", fs.Args())
+	}
+
+	cfg, err := config.Load()
+	if err != nil {
+		return err
+	}
+	settings := cfg.Settings()
+	pricing.Configure(settings.Pricing)
+	loc := settings.Location
+	if loc == nil {
+		loc = time.Local
+	}
+	opts := report.Options{
+		By:        *by,
+		Location:  loc,
+		WeekStart: settings.WeekStart,
+		Top:       *top,
+	}
+	if *since != "" {
+		if opts.Since, err = time.ParseInLocation(time.DateOnly, *since, loc); err != nil {
+			return fmt.Errorf("parse since: %w", err)
+		}
+	}
+	if *until != "" {
+		if opts.Until, err = time.ParseInLocation(time.DateOnly, *until, loc); err != nil {
+			return fmt.Errorf("parse until: %w", err)
+		}
+		// Until is inclusive on the command line.
+		opts.Until = opts.Until.AddDate(0, 0, 1)
+	}
+
+	roots, err := transcript.DefaultRoots()
+	if err != nil {
+		return err
+	}
+	transcripts, err := transcript.ParseRoots(append(roots, settings.Roots...))
+	if err != nil {
+		return err
+	}
+	rows, err := report.Build(transcripts, opts)
+	if err != nil {
+		return err
+	}
+	return report.Write(w, rows, *format)
+}
@@ -0,0 +1,334 @@
+package config
+
+import (
+	"bytes"
+	_ "embed"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/BurntSushi/toml"
+	"go.yaml.in/yaml/v3"
+
+	"github.com/iskorotkov/cc-statusline/dirs"
+	"github.com/iskorotkov/cc-statusline/parts"
+	"github.com/iskorotkov/cc-statusline/pricing"
+	"github.com/iskorotkov/cc-statusline/style"
+	"github.com/iskorotkov/cc-statusline/transcript"
+)
+
+//go:embed default.toml
+var defaultConfig []byte
+
+var extensions = []string{".toml", ".yaml", ".yml", ".json"}
+
+type Config struct {
+	// Deadline limits the time spent rendering the statusline.
+	Deadline Duration `json:"deadline"`
+	// Placeholder is shown for parts that miss the deadline.
+	Placeholder string `json:"placeholder"`
+	// Strict fails the whole statusline when any part fails.
+	Strict bool `json:"strict"`
+	// ErrorMarker is a template shown in place of failed parts.
+	ErrorMarker string `json:"error_marker"`
+	// DebugLog is the path of the debug log, "off" disables it.
+	DebugLog string `json:"debug_log"`
+	// Cache is the time to live of lookups cached on disk, by source name.
+	Cache map[string]Duration `json:"cache"`
+	// Timezone of calendar usage windows, an IANA name or "" for local time.
+	Timezone Timezone `json:"timezone"`
+	// WeekStart is the first day of calendar weeks.
+	WeekStart Weekday `json:"week_start"`
+	// Budget limits the cost of usage windows.
+	Budget Budget `json:"budget"`
+	// TranscriptRoots are Claude config dirs read in addition to the one
+	// used by Claude Code.
+	TranscriptRoots []TranscriptRoot `json:"transcript_roots"`
+	// Pricing adds or replaces model prices, by model ID or prefix, with an
+	// optional "bedrock/" or "vertex/" provider prefix.
+	Pricing map[string]ModelPrice `json:"pricing"`
+	Rows    []Row                 `json:"rows"`
+}
+
+// Budget holds cost budgets in dollars by window, 0 for no budget.
+type Budget struct {
+	Session float64 `json:"session"`
+	Day     float64 `json:"day"`
+	Week    float64 `json:"week"`
+	Month   float64 `json:"month"`
+	// Warn and Alert are percentages of the budget.
+	Warn  float64 `json:"warn"`
+	Alert float64 `json:"alert"`
+	// Command runs once per window when a threshold is first reached.
+	Command string `json:"command"`
+}
+
+// TranscriptRoot is a Claude config dir with transcripts. The label
+// defaults to the base name of the dir.
+type TranscriptRoot struct {
+	Label string `json:"label"`
+	Dir   string `json:"dir"`
+}
+
+// ModelPrice is the price of a model in dollars per million tokens. Cache
+// prices default to 1.25x, 2x and 0.1x of the input price for 5-minute
+// writes, 1-hour writes and reads.
+type ModelPrice struct {
+	Input        float64 `json:"input"`
+	Output       float64 `json:"output"`
+	CacheWrite   float64 `json:"cache_write"`
+	CacheWrite1h float64 `json:"cache_write_1h"`
+	CacheRead    float64 `json:"cache_read"`
+	// LongContextInput and LongContextOutput multiply input and output
+	// prices of requests over 200K input tokens, 0 for no long context rates.
+	LongContextInput  float64 `json:"long_context_input"`
+	LongContextOutput float64 `json:"long_context_output"`
+	// WebSearch and WebFetch are prices per server tool request in dollars,
+	// 0 for the built-in prices.
+	WebSearch float64 `json:"web_search"`
+	WebFetch  float64 `json:"web_fetch"`
+}
+
+type Row struct {
+	Prefix string `json:"prefix"`
+	Parts  []Part `json:"parts"`
+}
+
+// Part references a part by name. In config files it is either a plain
+// string or a table with a "name" key and part parameters.
+type Part struct {
+	Name   string
+	Params map[string]any
+}
+
+func (p *Part) UnmarshalJSON(data []byte) error {
+	*p = Part{}
+	if err := json.Unmarshal(data, &p.Name); err == nil {
+		return nil
+	}
+	var params map[string]any
+	if err := json.Unmarshal(data, &params); err != nil {
+		return fmt.Errorf("part must be a name or a table: %w", err)
+	}
+	name, ok := params["name"].(string)
+	if !ok || name == "" {
+		return errors.New("part table must have a name")
+	}
+	delete(params, "name")
+	p.Name = name
+	p.Params = params
+	return nil
+}
+
+func Default() Config {
+	var cfg Config
+	if err := decode(".toml", defaultConfig, &cfg); err != nil {
+		panic(fmt.Sprintf("decode default config: %v", err))
+	}
+	return cfg
+}
+
+// Load reads the config file from the user config dir or from the path in
+// CC_STATUSLINE_CONFIG. A missing config file yields the default config.
+func Load() (Config, error) {
+	if path := os.Getenv("CC_STATUSLINE_CONFIG"); path != "" {
+		return LoadFile(path)
+	}
+	dir, err := dirs.Config()
+	if err != nil {
+		return Default(), err
+	}
+	for _, ext := range extensions {
+		path := filepath.Join(dir, "config"+ext)
+		if _, err := os.Stat(path); errors.Is(err, fs.ErrNotExist) {
+			continue
+		}
+		return LoadFile(path)
+	}
+	return Default(), nil
+}
+
+// LoadFile reads the config file at path. Settings missing from the file
+// keep their default values.
+func LoadFile(path string) (Config, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return Default(), fmt.Errorf("read config %q: %w", path, err)
+	}
+	cfg := Default()
+	// Rows are replaced as a whole rather than merged with the default ones.
+	rows := cfg.Rows
+	cfg.Rows = nil
+	if err := decode(filepath.Ext(path), data, &cfg); err != nil {
+		return Default(), fmt.Errorf("parse config %q: %w", path, err)
+	}
+	if cfg.Rows == nil {
+		cfg.Rows = rows
+	}
+	return cfg, nil
+}
+
+// Duration is a time.Duration written as a string such as "300ms".
+type Duration time.Duration
+
+func (d *Duration) UnmarshalJSON(data []byte) error {
+	var s string
+	if err := json.Unmarshal(data, &s); err != nil {
+		return fmt.Errorf("duration must be a string: %w", err)
+	}
+	v, err := time.ParseDuration(s)
+	if err != nil {
+		return err
+	}
+	*d = Duration(v)
+	return nil
+}
+
+// Timezone is a time.Location written as an IANA name such as
+// "Europe/Moscow". An empty name means local time.
+type Timezone struct {
+	*time.Location
+}
+
+func (tz *Timezone) UnmarshalJSON(data []byte) error {
+	var s string
+	if err := json.Unmarshal(data, &s); err != nil {
+		return fmt.Errorf("timezone must be a string: %w", err)
+	}
+	if s == "" {
+		*tz = Timezone{}
+		return nil
+	}
+	loc, err := time.LoadLocation(s)
+	if err != nil {
+		return err
+	}
+	*tz = Timezone{loc}
+	return nil
+}
+
+// Weekday is a time.Weekday written as a day name such as "monday".
+type Weekday time.Weekday
+
+func (d *Weekday) UnmarshalJSON(data []byte) error {
+	var s string
+	if err := json.Unmarshal(data, &s); err != nil {
+		return fmt.Errorf("weekday must be a string: %w", err)
+	}
+	for day := time.Sunday; day <= time.Saturday; day++ {
+		if strings.EqualFold(s, day.String()) {
+			*d = Weekday(day)
+			return nil
+		}
+	}
+	return fmt.Errorf("unknown weekday %q", s)
+}
+
+func (c Config) Settings() parts.Settings {
+	ttl := make(map[string]time.Duration, len(c.Cache))
+	for name, d := range c.Cache {
+		ttl[name] = time.Duration(d)
+	}
+	roots := make([]transcript.Root, 0, len(c.TranscriptRoots))
+	for _, r := range c.TranscriptRoots {
+		label := r.Label
+		if label == "" {
+			label = filepath.Base(r.Dir)
+		}
+		roots = append(roots, transcript.Root{Label: label, Dir: r.Dir})
+	}
+	prices := make(map[string]pricing.Pricing, len(c.Pricing))
+	for model, p := range c.Pricing {
+		if p.CacheWrite == 0 {
+			p.CacheWrite = p.Input * 1.25
+		}
+		if p.CacheWrite1h == 0 {
+			p.CacheWrite1h = p.Input * 2
+		}
+		if p.CacheRead == 0 {
+			p.CacheRead = p.Input * 0.1
+		}
+		prices[model] = pricing.Pricing{
+			InputTokens:        p.Input / 1e6,
+			OutputTokens:       p.Output / 1e6,
+			CacheWriteTokens:   p.CacheWrite / 1e6,
+			CacheWrite1hTokens: p.CacheWrite1h / 1e6,
+			CacheReadTokens:    p.CacheRead / 1e6,
+			LongContextInput:   p.LongContextInput,
+			LongContextOutput:  p.LongContextOutput,
+			WebSearchRequests:  p.WebSearch,
+			WebFetchRequests:   p.WebFetch,
+		}
+	}
+	return parts.Settings{
+		Placeholder: c.Placeholder,
+		Strict:      c.Strict,
+		ErrorMarker: c.ErrorMarker,
+		CacheTTL:    ttl,
+		Roots:       roots,
+		Location:    c.Timezone.Location,
+		WeekStart:   time.Weekday(c.WeekStart),
+		Budget: parts.Budget{
+			Limits: map[string]float64{
+				parts.WindowSession: c.Budget.Session,
+				parts.WindowDay:     c.Budget.Day,
+				parts.WindowWeek:    c.Budget.Week,
+				parts.WindowMonth:   c.Budget.Month,
+			},
+			Warn:    c.Budget.Warn,
+			Alert:   c.Budget.Alert,
+			Command: c.Budget.Command,
+		},
+		Pricing: prices,
+	}
+}
+
+func (c Config) Layout() (parts.Part, error) {
+	rows := make([]parts.Part, 0, len(c.Rows))
+	for i, r := range c.Rows {
+		row := make([]parts.Part, 0, len(r.Parts))
+		for _, p := range r.Parts {
+			part, err := parts.New(p.Name, p.Params)
+			if err != nil {
+				return nil, fmt.Errorf("row %d: %w", i+1, err)
+			}
+			row = append(row, part)
+		}
+		rows = append(rows, parts.Row(style.Dim(style.Blue(r.Prefix)), row...))
+	}
+	return parts.Rows(rows...), nil
+}
+
+// decode parses TOML, YAML or JSON into a generic form and then decodes it
+// as JSON, so all formats share the same keys and validation.
+func decode(ext string, data []byte, cfg *Config) error {
+	var raw map[string]any
+	switch strings.ToLower(ext) {
+	case ".toml":
+		if err := toml.Unmarshal(data, &raw); err != nil {
+			return err
+		}
+	case ".yaml", ".yml":
+		if err := yaml.Unmarshal(data, &raw); err != nil {
+			return err
+		}
+	case ".json":
+		if err := json.Unmarshal(data, &raw); err != nil {
+			return err
+		}
+	default:
+		return fmt.Errorf("unsupported config format %q", ext)
+	}
+	b, err := json.Marshal(raw)
+	if err != nil {
+		return err
+	}
+	dec := json.NewDecoder(bytes.NewReader(b))
+	dec.DisallowUnknownFields()
+	return dec.Decode(cfg)
+}
@@ -0,0 +1,177 @@
+package config_test
+
+import (
+	"math"
+	"os"
+	"path/filepath"
+	"slices"
+	"testing"
+	"time"
+
+	"github.com/iskorotkov/cc-statusline/config"
+	"github.com/iskorotkov/cc-statusline/parts"
+	"github.com/iskorotkov/cc-statusline/pricing"
+)
+
+func TestDefault(t *testing.T) {
+	cfg := config.Default()
+	if len(cfg.Rows) == 0 {
+		t.Fatal("default config has no rows")
+	}
+	if _, err := cfg.Layout(); err != nil {
+		t.Fatalf("Layout() error: %v", err)
+	}
+}
+
+func TestLoadFile(t *testing.T) {
+	files := map[string]string{
+		"config.toml": `
+[[rows]]
+prefix = "CC"
+parts = ["cc.model", { name = "cc.version" }]
+`,
+		"config.yaml": `
+rows:
+  - prefix: CC
+    parts:
+      - cc.model
+      - name: cc.version
+`,
+		"config.json": `{"rows": [{"prefix": "CC", "parts": ["cc.model", {"name": "cc.version"}]}]}`,
+	}
+	for name, content := range files {
+		t.Run(name, func(t *testing.T) {
+			path := filepath.Join(t.TempDir(), name)
+			if err := os.WriteFile(path, []byte(content), 0o644); err != nil {
+				t.Fatal(err)
+			}
+			cfg, err := config.LoadFile(path)
+			if err != nil {
+				t.Fatalf("LoadFile() error: %v", err)
+			}
+			if len(cfg.Rows) != 1 || cfg.Rows[0].Prefix != "CC" {
+				t.Fatalf("unexpected rows: %+v", cfg.Rows)
+			}
+			var names []string
+			for _, p := range cfg.Rows[0].Parts {
+				names = append(names, p.Name)
+			}
+			if !slices.Equal(names, []string{"cc.model", "cc.version"}) {
+				t.Errorf("unexpected parts: %v", names)
+			}
+			if _, err := cfg.Layout(); err != nil {
+				t.Errorf("Layout() error: %v", err)
+			}
+		})
+	}
+}
+
+func TestLoadFileMissingRows(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "config.toml")
+	if err := os.WriteFile(path, nil, 0o644); err != nil {
+		t.Fatal(err)
+	}
+	cfg, err := config.LoadFile(path)
+	if err != nil {
+		t.Fatalf("LoadFile() error: %v", err)
+	}
+	if len(cfg.Rows) != len(config.Default().Rows) {
+		t.Errorf("expected default rows, got %+v", cfg.Rows)
+	}
+}
+
+func TestLoadFileCalendar(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "config.toml")
+	if err := os.WriteFile(path, []byte("timezone = \"UTC\"\nweek_start = \"Sunday\"\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	cfg, err := config.LoadFile(path)
+	if err != nil {
+		t.Fatalf("LoadFile() error: %v", err)
+	}
+	s := cfg.Settings()
+	if s.Location != time.UTC {
+		t.Errorf("Location = %v, want UTC", s.Location)
+	}
+	if s.WeekStart != time.Sunday {
+		t.Errorf("WeekStart = %v, want Sunday", s.WeekStart)
+	}
+	if d := config.Default().Settings(); d.Location != nil || d.WeekStart != time.Monday {
+		t.Errorf("default Location = %v, WeekStart = %v, want local time and Monday", d.Location, d.WeekStart)
+	}
+}
+
+func TestLoadFileBudget(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "config.toml")
+	if err := os.WriteFile(path, []byte("[budget]\nday = 20.0\ncommand = \"notify-send budget\"\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	cfg, err := config.LoadFile(path)
+	if err != nil {
+		t.Fatalf("LoadFile() error: %v", err)
+	}
+	b := cfg.Settings().Budget
+	if b.Limits["day"] != 20 || b.Limits["month"] != 0 {
+		t.Errorf("Limits = %v, want day budget only", b.Limits)
+	}
+	if b.Warn != 80 || b.Alert != 100 {
+		t.Errorf("Warn, Alert = %v, %v, want defaults 80, 100", b.Warn, b.Alert)
+	}
+	if b.Command != "notify-send budget" {
+		t.Errorf("Command = %q, want %q", b.Command, "notify-send budget")
+	}
+	if err := parts.Configure(cfg.Settings()); err != nil {
+		t.Errorf("Configure() error: %v", err)
+	}
+}
+
+func TestLoadFilePricing(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "config.toml")
+	content := "[pricing.\"claude-custom\"]\ninput = 2.0\noutput = 10.0\n"
+	if err := os.WriteFile(path, []byte(content), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	cfg, err := config.LoadFile(path)
+	if err != nil {
+		t.Fatalf("LoadFile() error: %v", err)
+	}
+	want := pricing.Pricing{InputTokens: 2e-6, OutputTokens: 10e-6, CacheWriteTokens: 2.5e-6, CacheWrite1hTokens: 4e-6, CacheReadTokens: 0.2e-6}
+	got := cfg.Settings().Pricing["claude-custom"]
+	if math.Abs(got.InputTokens-want.InputTokens) > 1e-12 || math.Abs(got.OutputTokens-want.OutputTokens) > 1e-12 ||
+		math.Abs(got.CacheWriteTokens-want.CacheWriteTokens) > 1e-12 || math.Abs(got.CacheWrite1hTokens-want.CacheWrite1hTokens) > 1e-12 ||
+		math.Abs(got.CacheReadTokens-want.CacheReadTokens) > 1e-12 {
+		t.Errorf("Pricing = %+v, want %+v", got, want)
+	}
+}
+
+func TestLoadFileInvalid(t *testing.T) {
+	files := map[string]string{
+		"syntax.toml":  `rows = [`,
+		"unknown.toml": `colors = true`,
+		"format.ini":   `rows = []`,
+		"tz.toml":      `timezone = "Mars/Olympus"`,
+		"week.toml":    `week_start = "someday"`,
+	}
+	for name, content := range files {
+		t.Run(name, func(t *testing.T) {
+			path := filepath.Join(t.TempDir(), name)
+			if err := os.WriteFile(path, []byte(content), 0o644); err != nil {
+				t.Fatal(err)
+			}
+			cfg, err := config.LoadFile(path)
+			if err == nil {
+				t.Fatal("expected error")
+			}
+			if len(cfg.Rows) != len(config.Default().Rows) {
+				t.Errorf("expected fallback to default rows, got %+v", cfg.Rows)
+			}
+		})
+	}
+}
+
+func TestLayoutUnknownPart(t *testing.T) {
+	cfg := config.Config{Rows: []config.Row{{Prefix: "X", Parts: []config.Part{{Name: "cc.unknown"}}}}}
+	if _, err := cfg.Layout(); err == nil {
+		t.Error("expected error for unknown part")
+	}
+}
@@ -0,0 +1,28 @@
+package dirs
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+)
+
+// Config returns the cc-statusline config dir, honoring XDG_CONFIG_HOME.
+func Config() (string, error) {
+	return xdg("XDG_CONFIG_HOME", ".config")
+}
+
+// Cache returns the cc-statusline cache dir, honoring XDG_CACHE_HOME.
+func Cache() (string, error) {
+	return xdg("XDG_CACHE_HOME", ".cache")
+}
+
+func xdg(env, fallback string) (string, error) {
+	if dir := os.Getenv(env); dir != "" {
+		return filepath.Join(dir, "cc-statusline"), nil
+	}
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return "", fmt.Errorf("get user home dir: %w", err)
+	}
+	return filepath.Join(home, fallback, "cc-statusline"), nil
+}
@@ -1,3 +1,8 @@
 module github.com/iskorotkov/cc-statusline
 
 go 1.25
+
+require (
+	github.com/BurntSushi/toml v1.5.0
+	go.yaml.in/yaml/v3 v3.0.4
+)
@@ -0,0 +1,43 @@
+package main
+
+import (
+	"fmt"
+	"io"
+	"log/slog"
+	"os"
+	"path/filepath"
+
+	"github.com/iskorotkov/cc-statusline/dirs"
+)
+
+// maxDebugLogSize is the size after which the debug log is started anew.
+const maxDebugLogSize = 1 << 20
+
+// openDebugLog sends slog output to the debug log at path, or to the
+// default debug log when path is empty. "off" disables logging.
+func openDebugLog(path string) (io.Closer, error) {
+	slog.SetDefault(slog.New(slog.DiscardHandler))
+	if path == "off" {
+		return io.NopCloser(nil), nil
+	}
+	if path == "" {
+		dir, err := dirs.Cache()
+		if err != nil {
+			return nil, err
+		}
+		path = filepath.Join(dir, "debug.log")
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		return nil, fmt.Errorf("create debug log dir: %w", err)
+	}
+	flags := os.O_CREATE | os.O_WRONLY | os.O_APPEND
+	if info, err := os.Stat(path); err == nil && info.Size() > maxDebugLogSize {
+		flags |= os.O_TRUNC
+	}
+	f, err := os.OpenFile(path, flags, 0o644)
+	if err != nil {
+		return nil, fmt.Errorf("open debug log: %w", err)
+	}
+	slog.SetDefault(slog.New(slog.NewTextHandler(f, nil)))
+	return f, nil
+}
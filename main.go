@@ -4,54 +4,17 @@ import (
 	"context"
 	"encoding/json"
 	"fmt"
+	"log/slog"
 	"os"
 	"os/signal"
+	"time"
 
+	"github.com/iskorotkov/cc-statusline/cache"
+	"github.com/iskorotkov/cc-statusline/config"
 	"github.com/iskorotkov/cc-statusline/parts"
 	"github.com/iskorotkov/cc-statusline/style"
 )
 
-var r = parts.Rows(
-	parts.Row(
-		style.Dim(style.Blue("CC")),
-		parts.CCVersion(),
-		parts.CCModel(),
-		parts.CCOutputStyle(),
-		parts.CCDir(),
-		parts.CCStats(),
-		parts.CC200KContextBadge(),
-	),
-	parts.Row(
-		style.Dim(style.Blue("API")),
-		parts.CCSessionUsage(),
-		parts.CCHourUsage(),
-		parts.CCDayUsage(),
-		parts.CCWeekUsage(),
-	),
-	parts.Row(
-		style.Dim(style.Blue("GIT")),
-		parts.GitRemoteOrigin(),
-		parts.GitBranch(),
-		parts.GitStatus(),
-		parts.GitDiffStats(),
-	),
-	parts.Row(
-		style.Dim(style.Blue("PR")),
-		parts.GHPRNumber(),
-		parts.GHPRTitle(),
-		parts.GHPRStats(),
-	),
-	parts.Row(
-		style.Dim(style.Blue("PR")),
-		parts.GHPRURL(),
-	),
-	parts.Row(
-		style.Dim(style.Blue("TASK")),
-		parts.GHIssueURL(),
-		parts.JiraURL(),
-	),
-)
-
 func main() {
 	defer func() {
 		if p := recover(); p != nil {
@@ -68,14 +31,94 @@ func main() {
 }
 
 func run(ctx context.Context) error {
+	if len(os.Args) > 1 {
+		switch os.Args[1] {
+		case "parts":
+			return listParts(ctx, os.Stdout)
+		case "index":
+			return indexCommand(os.Stdout, os.Args[2:])
+		case "report":
+			return reportCommand(os.Stdout, os.Args[2:])
+		case "pr-cost":
+			return prCostCommand(ctx, os.Stdout, os.Args[2:])
+		case cache.Command:
+			if len(os.Args) < 3 {
+				return fmt.Errorf("usage: %s %s <source>", os.Args[0], cache.Command)
+			}
+			return refresh(ctx, os.Args[2])
+		default:
+			return fmt.Errorf("unknown command %q", os.Args[1])
+		}
+	}
+	return statusline(ctx)
+}
+
+func statusline(ctx context.Context) error {
 	var hook parts.CCHook
 	if err := json.NewDecoder(os.Stdin).Decode(&hook); err != nil {
 		return err
 	}
-	s, err := r(ctx, hook)
+	cfg, l := load()
+	if f, err := openDebugLog(cfg.DebugLog); err == nil {
+		defer func() {
+			_ = f.Close()
+		}()
+	}
+	ctx, cancel := context.WithTimeout(ctx, time.Duration(cfg.Deadline))
+	defer cancel()
+	s, err := l(ctx, hook)
 	if err != nil {
 		return err
 	}
 	fmt.Print(s)
 	return nil
 }
+
+// load reads the user config and builds the statusline from it. An invalid
+// config falls back to the default one with the error shown in an extra row.
+func load() (config.Config, parts.Part) {
+	cfg, err := config.Load()
+	if err == nil {
+		var l parts.Part
+		if l, err = setup(cfg); err == nil {
+			return cfg, l
+		}
+	}
+	cfg = config.Default()
+	l, defaultErr := setup(cfg)
+	if defaultErr != nil {
+		panic(defaultErr)
+	}
+	return cfg, parts.Rows(
+		l,
+		parts.Row(
+			style.Dim(style.Red("CFG")),
+			parts.Fixed(style.Red(fmt.Sprintf("invalid config: %v, using default layout", err))),
+		),
+	)
+}
+
+// refresh updates a cached source. It runs detached from the statusline, so
+// errors go to the debug log only.
+func refresh(ctx context.Context, source string) error {
+	cfg, _ := config.Load()
+	if f, err := openDebugLog(cfg.DebugLog); err == nil {
+		defer func() {
+			_ = f.Close()
+		}()
+	}
+	ctx, cancel := context.WithTimeout(ctx, time.Minute)
+	defer cancel()
+	if err := parts.RefreshSource(ctx, source); err != nil {
+		slog.Error("refresh source", "source", source, "err", err)
+		return err
+	}
+	return nil
+}
+
+func setup(cfg config.Config) (parts.Part, error) {
+	if err := parts.Configure(cfg.Settings()); err != nil {
+		return nil, err
+	}
+	return cfg.Layout()
+}
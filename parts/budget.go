@@ -0,0 +1,50 @@
+package parts
+
+import (
+	"log/slog"
+
+	"github.com/iskorotkov/cc-statusline/alert"
+)
+
+// Budget limits the cost of usage windows.
+type Budget struct {
+	// Limits are cost budgets in dollars by window name.
+	Limits map[string]float64
+	// Warn and Alert are percentages of the budget at which the cost is
+	// shown as a warning or an alert.
+	Warn  float64
+	Alert float64
+	// Command runs once per window when the cost first reaches the warning
+	// or the alert level.
+	Command string
+}
+
+var budgetWindows = []string{WindowSession, WindowDay, WindowWeek, WindowMonth}
+
+// applyBudget sets the budget of the window, identified by key, and fires
+// the alert command when a threshold is reached. Alerts of parts limited to
+// different roots fire separately.
+func applyBudget(data *UsageData, window, root, key string) {
+	limit := settings.Budget.Limits[window]
+	if limit <= 0 {
+		return
+	}
+	data.Budget = limit
+	data.Percent = data.Cost / limit * 100
+	data.Level = level(data.Percent, settings.Budget.Warn, settings.Budget.Alert)
+	if data.Level == LevelOK || settings.Budget.Command == "" || key == "" {
+		return
+	}
+	a := alert.Alert{
+		Window:  window,
+		Key:     key,
+		Root:    root,
+		Level:   data.Level,
+		Cost:    data.Cost,
+		Budget:  data.Budget,
+		Percent: data.Percent,
+	}
+	if _, err := alert.Fire(settings.Budget.Command, a); err != nil {
+		slog.Warn("fire budget alert", "window", window, "level", data.Level, "err", err)
+	}
+}
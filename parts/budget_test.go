@@ -0,0 +1,89 @@
+package parts_test
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"runtime"
+	"slices"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/iskorotkov/cc-statusline/parts"
+	"github.com/iskorotkov/cc-statusline/style"
+)
+
+func TestBudget(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("alert command uses sh")
+	}
+	// Each message costs $0.45, so the days cost 45%, 90% and 135% of the
+	// budget.
+	writeTranscripts(t,
+		usageEvent("m1", "2025-01-01T10:00:00Z", 150_000),
+		usageEvent("m2", "2025-01-02T10:00:00Z", 150_000),
+		usageEvent("m3", "2025-01-02T11:00:00Z", 150_000),
+		usageEvent("m4", "2025-01-03T10:00:00Z", 150_000),
+		usageEvent("m5", "2025-01-03T11:00:00Z", 150_000),
+		usageEvent("m6", "2025-01-03T12:00:00Z", 150_000),
+	)
+	out := filepath.Join(t.TempDir(), "out")
+	configure(t, parts.Settings{Budget: parts.Budget{
+		Limits:  map[string]float64{parts.WindowDay: 1},
+		Warn:    50,
+		Alert:   100,
+		Command: `echo "$CC_BUDGET_WINDOW $CC_BUDGET_LEVEL $CC_BUDGET_ROOT $CC_BUDGET_COST/$CC_BUDGET_LIMIT" >> ` + out,
+	}})
+
+	tests := []struct {
+		day    int
+		params map[string]any
+		want   string
+	}{
+		{day: 1, want: style.Green("$0.5/$1")},
+		{day: 2, want: style.Yellow("$0.9/$1")},
+		// The alert of the window already fired.
+		{day: 2, want: style.Yellow("$0.9/$1")},
+		// Parts limited to a root fire their own alerts.
+		{day: 2, params: map[string]any{"root": "default"}, want: style.Yellow("$0.9/$1")},
+		{day: 3, want: style.Red("$1.4/$1")},
+	}
+	for _, tt := range tests {
+		parts.SetNow(t, time.Date(2025, 1, tt.day, 20, 0, 0, 0, time.UTC))
+		params := map[string]any{"format": `{{if .Budget}}{{level .Level (printf "%s/$%.0f" (money .Cost) .Budget)}}{{end}}`}
+		for k, v := range tt.params {
+			params[k] = v
+		}
+		p, err := parts.New("api.day", params)
+		if err != nil {
+			t.Fatalf("New() error: %v", err)
+		}
+		got, err := p(context.Background(), parts.CCHook{})
+		if err != nil {
+			t.Fatalf("part error: %v", err)
+		}
+		if got != tt.want {
+			t.Errorf("day %d part = %q, want %q", tt.day, got, tt.want)
+		}
+	}
+
+	// Commands run in the background, so their output may be in any order.
+	want := []string{"day alert  1.35/1.00", "day warn  0.90/1.00", "day warn default 0.90/1.00"}
+	deadline := time.Now().Add(5 * time.Second)
+	for {
+		data, _ := os.ReadFile(out)
+		lines := strings.Split(strings.TrimSpace(string(data)), "\n")
+		if len(lines) >= len(want) {
+			slices.Sort(lines)
+			if !slices.Equal(lines, want) {
+				t.Errorf("command output = %q, want %q", lines, want)
+			}
+			break
+		}
+		if time.Now().After(deadline) {
+			t.Fatalf("command output = %q, want %q", data, want)
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+}
@@ -0,0 +1,83 @@
+package parts
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/iskorotkov/cc-statusline/transcript"
+)
+
+// Periods the burn rate is projected to the end of.
+const (
+	PeriodBlock = "block"
+	PeriodDay   = "day"
+)
+
+type BurnRateOptions struct {
+	// Window is the trailing window the rate is computed over.
+	Window time.Duration
+	// Period is PeriodBlock or PeriodDay.
+	Period string
+	// Warn and Alert are thresholds of the cost per hour.
+	Warn   float64
+	Alert  float64
+	Root   string
+	Format string
+}
+
+type BurnRateData struct {
+	TokensPerMinute int
+	CostPerHour     float64
+	// Projected is the cost of the period if the rate holds until it ends.
+	Projected       float64
+	ProjectedTokens int
+	Period          string
+	// Level is "ok", "warn" or "alert" depending on the cost per hour.
+	Level string
+}
+
+// CCBurnRate shows tokens per minute and cost per hour over a trailing
+// window, and the cost projected to the end of the current block or day.
+// With the block period, it is hidden when no block is active.
+func CCBurnRate(opts BurnRateOptions) (Part, error) {
+	if opts.Window <= 0 {
+		return nil, fmt.Errorf("window must be positive, got %s", opts.Window)
+	}
+	if opts.Period != PeriodBlock && opts.Period != PeriodDay {
+		return nil, fmt.Errorf("period must be %q or %q, got %q", PeriodBlock, PeriodDay, opts.Period)
+	}
+	return Formatted(opts.Format, func(ctx context.Context, h CCHook) (BurnRateData, bool, error) {
+		transcripts, err := parsedTranscripts(ctx)
+		if err != nil {
+			return BurnRateData{}, false, err
+		}
+		if opts.Root != "" {
+			transcripts = transcript.FilterRoot(transcripts, opts.Root)
+		}
+		t := now()
+		var from, to time.Time
+		switch opts.Period {
+		case PeriodBlock:
+			b, ok := transcript.ActiveBlock(transcripts, t)
+			if !ok {
+				return BurnRateData{}, false, nil
+			}
+			from, to = b.Start, b.End
+		case PeriodDay:
+			from, to = transcript.Day(t)
+		}
+		recent := usageData("", transcript.DateUsage(transcripts, t.Add(-opts.Window), t))
+		total := usageData("", transcript.DateUsage(transcripts, from, t))
+		data := BurnRateData{
+			TokensPerMinute: int(float64(recent.Tokens) / opts.Window.Minutes()),
+			CostPerHour:     recent.Cost / opts.Window.Hours(),
+			Period:          opts.Period,
+		}
+		left := to.Sub(t)
+		data.Projected = total.Cost + data.CostPerHour*left.Hours()
+		data.ProjectedTokens = total.Tokens + int(float64(recent.Tokens)/opts.Window.Minutes()*left.Minutes())
+		data.Level = level(data.CostPerHour, opts.Warn, opts.Alert)
+		return data, true, nil
+	})
+}
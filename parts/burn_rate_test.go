@@ -0,0 +1,56 @@
+package parts_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/iskorotkov/cc-statusline/parts"
+)
+
+func TestBurnRate(t *testing.T) {
+	// The block of the first message ends at 07:00, so the second block
+	// starts at 08:00. The last 30 minutes have 300K tokens, $0.9.
+	writeTranscripts(t,
+		usageEvent("m1", "2025-01-01T02:00:00Z", 150_000),
+		usageEvent("m2", "2025-01-01T08:00:00Z", 150_000),
+		usageEvent("m3", "2025-01-01T12:10:00Z", 150_000),
+		usageEvent("m4", "2025-01-01T12:20:00Z", 150_000),
+	)
+	format := `{{.Period}} {{.TokensPerMinute}} {{printf "%.2f" .CostPerHour}} {{printf "%.2f" .Projected}} {{.ProjectedTokens}} {{.Level}}`
+
+	tests := []struct {
+		period string
+		now    time.Time
+		want   string
+	}{
+		// 30 minutes left: $1.35 + $1.8/h * 0.5h, 450K + 10K/min * 30min.
+		{period: parts.PeriodBlock, now: time.Date(2025, 1, 1, 12, 30, 0, 0, time.UTC), want: "block 10000 1.80 2.25 750000 warn"},
+		// 11.5 hours left: $1.8 + $1.8/h * 11.5h, 600K + 10K/min * 690min.
+		{period: parts.PeriodDay, now: time.Date(2025, 1, 1, 12, 30, 0, 0, time.UTC), want: "day 10000 1.80 22.50 7500000 warn"},
+		// The block ended at 13:00 and no message was sent since.
+		{period: parts.PeriodBlock, now: time.Date(2025, 1, 1, 13, 30, 0, 0, time.UTC), want: ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.period+" "+tt.now.Format(time.Kitchen), func(t *testing.T) {
+			parts.SetNow(t, tt.now)
+			p, err := parts.New("api.burn_rate", map[string]any{
+				"window": "30m",
+				"period": tt.period,
+				"warn":   1.0,
+				"alert":  2.0,
+				"format": format,
+			})
+			if err != nil {
+				t.Fatalf("New() error: %v", err)
+			}
+			got, err := p(context.Background(), parts.CCHook{})
+			if err != nil {
+				t.Fatalf("part error: %v", err)
+			}
+			if got != tt.want {
+				t.Errorf("part = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
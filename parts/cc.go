@@ -2,11 +2,8 @@ package parts
 
 import (
 	"context"
-	"fmt"
 	"path/filepath"
 	"time"
-
-	"github.com/iskorotkov/cc-statusline/style"
 )
 
 type CCHook struct {
@@ -35,56 +32,64 @@ type CCHook struct {
 	Exceeds200KTokens bool `json:"exceeds_200k_tokens"`
 }
 
-func CCVersion() Part {
-	return func(ctx context.Context, h CCHook) (string, error) {
-		return fmt.Sprintf(style.Dim("v%s"), h.Version), nil
-	}
+type CCDirData struct {
+	Dir string
+}
+
+type CCStatsData struct {
+	LinesAdded   int
+	LinesRemoved int
+	Duration     time.Duration
+	APIDuration  time.Duration
+	Cost         float64
+}
+
+func CCVersion(format string) (Part, error) {
+	return Formatted(format, hookData)
 }
 
-func CCModel() Part {
-	return func(ctx context.Context, h CCHook) (string, error) {
-		return style.Bold(h.Model.DisplayName), nil
-	}
+func CCModel(format string) (Part, error) {
+	return Formatted(format, hookData)
 }
 
-func CCOutputStyle() Part {
-	return func(ctx context.Context, h CCHook) (string, error) {
-		return style.Dim(h.OutputStyle.Name), nil
-	}
+func CCOutputStyle(format string) (Part, error) {
+	return Formatted(format, hookData)
 }
 
-func CCDir() Part {
-	return func(ctx context.Context, h CCHook) (string, error) {
+func CCDir(maxLen int, format string) (Part, error) {
+	return Formatted(format, func(ctx context.Context, h CCHook) (CCDirData, bool, error) {
 		dir, err := filepath.Rel(filepath.Dir(h.Workspace.ProjectDir), h.Workspace.CurrentDir)
 		if err != nil {
-			return "", err
+			return CCDirData{}, false, err
 		}
-		return style.Italic(limit(dir, 20)), nil
-	}
+		return CCDirData{Dir: limit(dir, maxLen)}, true, nil
+	})
 }
 
-func CCStats() Part {
-	return func(ctx context.Context, h CCHook) (string, error) {
-		duration := time.Millisecond * time.Duration(h.Cost.TotalAPIDurationMS)
-		return fmt.Sprintf("%s %s %.1fm %s",
-			style.Green(fmt.Sprintf("+%dL", h.Cost.TotalLinesAdded)),
-			style.Red(fmt.Sprintf("-%dL", h.Cost.TotalLinesRemoved)),
-			duration.Minutes(),
-			style.Green(fmt.Sprintf("$%.1f", h.Cost.TotalCostUSD))), nil
-	}
+func CCStats(format string) (Part, error) {
+	return Formatted(format, func(ctx context.Context, h CCHook) (CCStatsData, bool, error) {
+		return CCStatsData{
+			LinesAdded:   h.Cost.TotalLinesAdded,
+			LinesRemoved: h.Cost.TotalLinesRemoved,
+			Duration:     time.Millisecond * time.Duration(h.Cost.TotalDurationMS),
+			APIDuration:  time.Millisecond * time.Duration(h.Cost.TotalAPIDurationMS),
+			Cost:         h.Cost.TotalCostUSD,
+		}, true, nil
+	})
 }
 
-func CC200KContextBadge() Part {
-	return func(ctx context.Context, h CCHook) (string, error) {
-		if h.Exceeds200KTokens {
-			return style.Bold("200K+"), nil
-		}
-		return "", nil
-	}
+func CC200KContextBadge(format string) (Part, error) {
+	return Formatted(format, func(ctx context.Context, h CCHook) (CCHook, bool, error) {
+		return h, h.Exceeds200KTokens, nil
+	})
+}
+
+func CCTranscriptPath(format string) (Part, error) {
+	return Formatted(format, func(ctx context.Context, h CCHook) (CCHook, bool, error) {
+		return h, h.TranscriptPath != "", nil
+	})
 }
 
-func CCTranscriptPath() Part {
-	return func(ctx context.Context, h CCHook) (string, error) {
-		return style.Dim(h.TranscriptPath), nil
-	}
+func hookData(ctx context.Context, h CCHook) (CCHook, bool, error) {
+	return h, true, nil
 }
@@ -0,0 +1,52 @@
+package parts
+
+import (
+	"context"
+
+	"github.com/iskorotkov/cc-statusline/transcript"
+)
+
+type BranchData struct {
+	Label  string
+	Branch string
+	Tokens int
+	Cost   float64
+	Models map[string]transcript.Usage
+	// Unpriced lists models with usage but no known pricing.
+	Unpriced []string
+}
+
+// CCBranchUsage shows usage of all sessions of the project on the current
+// git branch. It is hidden outside git repos and on branches without usage.
+func CCBranchUsage(opts UsageOptions) (Part, error) {
+	return Formatted(opts.Format, func(ctx context.Context, h CCHook) (BranchData, bool, error) {
+		branch, _ := gitBranchShowCurrent(ctx)
+		dir := h.Workspace.ProjectDir
+		if dir == "" {
+			dir = h.CWD
+		}
+		if branch == "" || dir == "" {
+			return BranchData{}, false, nil
+		}
+		transcripts, err := parsedTranscripts(ctx)
+		if err != nil {
+			return BranchData{}, false, err
+		}
+		if opts.Root != "" {
+			transcripts = transcript.FilterRoot(transcripts, opts.Root)
+		}
+		transcripts = transcript.FilterProjectTree(transcripts, dir)
+		usage := usageData(opts.Label, transcript.BranchUsage(transcripts, branch))
+		if usage.Tokens == 0 {
+			return BranchData{}, false, nil
+		}
+		return BranchData{
+			Label:    usage.Label,
+			Branch:   branch,
+			Tokens:   usage.Tokens,
+			Cost:     usage.Cost,
+			Models:   usage.Models,
+			Unpriced: usage.Unpriced,
+		}, true, nil
+	})
+}
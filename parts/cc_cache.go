@@ -0,0 +1,77 @@
+package parts
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/iskorotkov/cc-statusline/pricing"
+	"github.com/iskorotkov/cc-statusline/transcript"
+)
+
+type CacheOptions struct {
+	Label string
+	// Period is "session" or "day".
+	Period string
+	Root   string
+	Format string
+}
+
+type CacheData struct {
+	Label string
+	// InputTokens, WriteTokens and ReadTokens are uncached input, cache
+	// writes and cache reads.
+	InputTokens int
+	WriteTokens int
+	ReadTokens  int
+	// Ratio is the percentage of input tokens read from the cache.
+	Ratio float64
+	// Savings is the cost saved compared to billing all cached tokens as
+	// uncached input, negative when cache writes cost more than reads saved.
+	Savings float64
+}
+
+// CCCacheUsage shows how much of the input of the session or day was read
+// from the prompt cache, and how much that saved. It is hidden until there
+// is any input.
+func CCCacheUsage(opts CacheOptions) (Part, error) {
+	var parse func(context.Context, CCHook) ([]transcript.Transcript, error)
+	var usage func([]transcript.Transcript, CCHook) map[string]transcript.Usage
+	switch opts.Period {
+	case WindowSession:
+		parse = sessionTranscripts
+		usage = func(transcripts []transcript.Transcript, h CCHook) map[string]transcript.Usage {
+			return transcript.SessionUsage(transcripts, h.SessionID)
+		}
+	case WindowDay:
+		parse = allTranscripts
+		usage = func(transcripts []transcript.Transcript, _ CCHook) map[string]transcript.Usage {
+			from, to := transcript.Day(now())
+			return transcript.DateUsage(transcripts, from, to)
+		}
+	default:
+		return nil, fmt.Errorf("period must be %q or %q, got %q", WindowSession, WindowDay, opts.Period)
+	}
+	return Formatted(opts.Format, func(ctx context.Context, h CCHook) (CacheData, bool, error) {
+		transcripts, err := parse(ctx, h)
+		if err != nil {
+			return CacheData{}, false, err
+		}
+		if opts.Root != "" {
+			transcripts = transcript.FilterRoot(transcripts, opts.Root)
+		}
+		data := CacheData{Label: opts.Label}
+		for model, usage := range usage(transcripts, h) {
+			data.InputTokens += usage.InputTokens
+			data.WriteTokens += usage.CacheWriteTokens
+			data.ReadTokens += usage.CacheReadTokens
+			savings, _ := pricing.CacheSavings(model, usage)
+			data.Savings += savings
+		}
+		input := data.InputTokens + data.WriteTokens + data.ReadTokens
+		if input == 0 {
+			return CacheData{}, false, nil
+		}
+		data.Ratio = float64(data.ReadTokens) / float64(input) * 100
+		return data, true, nil
+	})
+}
@@ -0,0 +1,81 @@
+package parts
+
+import (
+	"cmp"
+	"context"
+	"strings"
+
+	"github.com/iskorotkov/cc-statusline/pricing"
+	"github.com/iskorotkov/cc-statusline/transcript"
+)
+
+// Context window sizes of Claude models. Models with a 1M token context are
+// marked with a "[1m]" suffix in the hook model ID, and models missing from
+// the model table get the default one.
+const (
+	defaultContextWindow = 200_000
+	longContextWindow    = 1_000_000
+)
+
+type ContextOptions struct {
+	// Window overrides the context window size of the model, 0 for auto.
+	Window int
+	// Warn and Alert are context usage percentages shown as a warning or an
+	// alert, set below the point where Claude Code compacts the context.
+	Warn   float64
+	Alert  float64
+	Format string
+}
+
+type ContextData struct {
+	Tokens int
+	Window int
+	// Left is the number of tokens left in the context window.
+	Left    int
+	Percent float64
+	// Level is "ok", "warn" or "alert" depending on the percentage.
+	Level string
+	Model string
+}
+
+// CCContext shows how much of the model context window the session uses,
+// based on the latest message in the session transcript. It is hidden until
+// the session has messages.
+func CCContext(opts ContextOptions) (Part, error) {
+	return Formatted(opts.Format, func(ctx context.Context, h CCHook) (ContextData, bool, error) {
+		transcripts, err := sessionTranscripts(ctx, h)
+		if err != nil {
+			return ContextData{}, false, err
+		}
+		tokens, model, ok := transcript.Context(transcripts, h.SessionID)
+		if !ok {
+			return ContextData{}, false, nil
+		}
+		window := opts.Window
+		if window <= 0 {
+			window = contextWindow(h.Model.ID, model)
+		}
+		data := ContextData{
+			Tokens:  tokens,
+			Window:  window,
+			Left:    max(0, window-tokens),
+			Percent: float64(tokens) / float64(window) * 100,
+			Model:   model,
+		}
+		data.Level = level(data.Percent, opts.Warn, opts.Alert)
+		return data, true, nil
+	})
+}
+
+// contextWindow returns the context window size of the model of the latest
+// message, or of the hook model if the message has none. The "[1m]" suffix
+// of the hook model takes precedence, since transcripts do not record it.
+func contextWindow(hookModel, model string) int {
+	if strings.HasSuffix(strings.ToLower(hookModel), "[1m]") {
+		return longContextWindow
+	}
+	if window, ok := pricing.ContextWindow(cmp.Or(model, hookModel)); ok {
+		return window
+	}
+	return defaultContextWindow
+}
@@ -0,0 +1,46 @@
+package parts_test
+
+import (
+	"context"
+	"fmt"
+	"testing"
+
+	"github.com/iskorotkov/cc-statusline/parts"
+)
+
+func TestContextWindow(t *testing.T) {
+	tests := []struct {
+		name      string
+		model     string
+		hookModel string
+		window    int
+		want      string
+	}{
+		{name: "transcript model", model: "claude-2.0", hookModel: "claude-sonnet-4-20250514", want: "50000/100000 50"},
+		{name: "hook model", hookModel: "claude-2.0", want: "50000/100000 50"},
+		{name: "unknown model", model: "gpt-5", want: "50000/200000 25"},
+		{name: "1m suffix", model: "claude-2.0", hookModel: "claude-sonnet-4-20250514[1m]", want: "50000/1000000 5"},
+		{name: "window param", model: "claude-2.0", hookModel: "claude-sonnet-4-20250514[1m]", window: 500_000, want: "50000/500000 10"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			path := writeTranscripts(t, fmt.Sprintf(`{"sessionId":"s1","timestamp":"2025-01-01T10:00:00Z","message":{"id":"m1","model":%q,"usage":{"input_tokens":10000,"cache_read_input_tokens":40000}}}`+"\n", tt.model))
+			p, err := parts.New("cc.context", map[string]any{
+				"window": tt.window,
+				"format": `{{.Tokens}}/{{.Window}} {{printf "%.0f" .Percent}}`,
+			})
+			if err != nil {
+				t.Fatalf("New() error: %v", err)
+			}
+			h := parts.CCHook{SessionID: "s1", TranscriptPath: path}
+			h.Model.ID = tt.hookModel
+			got, err := p(context.Background(), h)
+			if err != nil {
+				t.Fatalf("part error: %v", err)
+			}
+			if got != tt.want {
+				t.Errorf("part = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,92 @@
+package parts
+
+import (
+	"context"
+	"fmt"
+	"path/filepath"
+	"time"
+
+	"github.com/iskorotkov/cc-statusline/transcript"
+)
+
+type ProjectOptions struct {
+	Label string
+	// Period is "day", "week" for the last 7 days, or "month".
+	Period string
+	Root   string
+	Format string
+}
+
+type ProjectData struct {
+	Label string
+	// Project is the project dir, and Name its base name.
+	Project string
+	Name    string
+	Tokens  int
+	Cost    float64
+	Models  map[string]transcript.Usage
+	// Unpriced lists models of the project with usage but no known pricing.
+	Unpriced []string
+	// TotalTokens and TotalCost are usage of all projects in the period.
+	TotalTokens int
+	TotalCost   float64
+	// Share is the project cost as a percentage of the total cost.
+	Share float64
+}
+
+// CCProjectUsage shows usage of the current project next to usage of all
+// projects in the period.
+func CCProjectUsage(opts ProjectOptions) (Part, error) {
+	var window func() (time.Time, time.Time)
+	switch opts.Period {
+	case WindowDay:
+		window = func() (time.Time, time.Time) {
+			return transcript.Day(now())
+		}
+	case WindowWeek:
+		window = func() (time.Time, time.Time) {
+			to := now()
+			return to.AddDate(0, 0, -7), to
+		}
+	case WindowMonth:
+		window = func() (time.Time, time.Time) {
+			return transcript.Month(now())
+		}
+	default:
+		return nil, fmt.Errorf("period must be %q, %q or %q, got %q", WindowDay, WindowWeek, WindowMonth, opts.Period)
+	}
+	return Formatted(opts.Format, func(ctx context.Context, h CCHook) (ProjectData, bool, error) {
+		dir := h.Workspace.ProjectDir
+		if dir == "" {
+			dir = h.CWD
+		}
+		if dir == "" {
+			return ProjectData{}, false, nil
+		}
+		transcripts, err := parsedTranscripts(ctx)
+		if err != nil {
+			return ProjectData{}, false, err
+		}
+		if opts.Root != "" {
+			transcripts = transcript.FilterRoot(transcripts, opts.Root)
+		}
+		from, to := window()
+		project := usageData(opts.Label, transcript.DateUsage(transcript.FilterProject(transcripts, dir), from, to))
+		total := usageData(opts.Label, transcript.DateUsage(transcripts, from, to))
+		data := ProjectData{
+			Label:       opts.Label,
+			Project:     dir,
+			Name:        filepath.Base(dir),
+			Tokens:      project.Tokens,
+			Cost:        project.Cost,
+			Models:      project.Models,
+			Unpriced:    project.Unpriced,
+			TotalTokens: total.Tokens,
+			TotalCost:   total.Cost,
+		}
+		if total.Cost > 0 {
+			data.Share = project.Cost / total.Cost * 100
+		}
+		return data, true, nil
+	})
+}
@@ -1,92 +1,301 @@
 package parts
 
 import (
+	"cmp"
 	"context"
 	"fmt"
+	"slices"
+	"strings"
 	"sync"
 	"time"
 
 	"github.com/iskorotkov/cc-statusline/pricing"
-	"github.com/iskorotkov/cc-statusline/style"
 	"github.com/iskorotkov/cc-statusline/transcript"
 )
 
-var parsedTranscripts = func() func(ctx context.Context) ([]transcript.Transcript, error) {
+// parsedTranscripts parses all transcripts once, the first time a part
+// spanning sessions is evaluated.
+var parsedTranscripts = parseTranscriptsOnce()
+
+// sessionTranscripts parses transcripts of the hook session once. Only the
+// project dir of the hook transcript is read, unless the hook has none.
+var sessionTranscripts = parseSessionOnce()
+
+func parseTranscriptsOnce() func(ctx context.Context) ([]transcript.Transcript, error) {
 	var transcripts []transcript.Transcript
 	var err error
 	var once sync.Once
 	return func(ctx context.Context) ([]transcript.Transcript, error) {
 		once.Do(func() {
-			transcripts, err = transcript.ParseTranscripts()
+			var roots []transcript.Root
+			roots, err = transcriptRoots()
+			if err != nil {
+				return
+			}
+			transcripts, err = transcript.ParseRoots(roots)
 		})
 		return transcripts, err
 	}
-}()
+}
 
-func CCSessionUsage() Part {
-	return func(ctx context.Context, h CCHook) (string, error) {
-		transcripts, err := parsedTranscripts(ctx)
-		if err != nil {
-			return "", err
+func parseSessionOnce() func(ctx context.Context, h CCHook) ([]transcript.Transcript, error) {
+	var transcripts []transcript.Transcript
+	var err error
+	var once sync.Once
+	return func(ctx context.Context, h CCHook) ([]transcript.Transcript, error) {
+		if h.TranscriptPath == "" {
+			return parsedTranscripts(ctx)
 		}
-		usage := transcript.SessionUsage(transcripts, h.SessionID)
-		return formatUsage("session", usage), nil
+		once.Do(func() {
+			var roots []transcript.Root
+			roots, err = transcriptRoots()
+			if err != nil {
+				return
+			}
+			transcripts, err = transcript.ParseSession(roots, h.TranscriptPath, h.SessionID)
+		})
+		return transcripts, err
 	}
 }
 
-func CCHourUsage() Part {
-	return func(ctx context.Context, h CCHook) (string, error) {
-		transcripts, err := parsedTranscripts(ctx)
-		if err != nil {
-			return "", err
-		}
-		from := time.Now().Truncate(time.Hour)
-		to := from.Add(time.Hour)
-		usage := transcript.DateUsage(transcripts, from, to)
-		return formatUsage("hour", usage), nil
+func transcriptRoots() ([]transcript.Root, error) {
+	roots, err := transcript.DefaultRoots()
+	if err != nil {
+		return nil, err
 	}
+	return append(roots, settings.Roots...), nil
+}
+
+// UsageOptions configures the API usage parts.
+type UsageOptions struct {
+	Label string
+	// Root limits usage to the Claude config dir with this label.
+	Root string
+	// Models is the number of models listed in the breakdown, 0 for none.
+	Models int
+	Format string
+}
+
+type UsageData struct {
+	Label  string
+	Tokens int
+	Cost   float64
+	Models map[string]transcript.Usage
+	// Unpriced lists models with usage but no known pricing, whose cost is
+	// estimated from their family or left out.
+	Unpriced []string
+	// WebSearchRequests and WebFetchRequests count server tool requests,
+	// which are included in the cost.
+	WebSearchRequests int
+	WebFetchRequests  int
+	// Breakdown lists usage by model family, most expensive first, if the
+	// part lists models.
+	Breakdown []ModelUsage
+	// Budget is the cost budget of the window, 0 if it has none.
+	Budget float64
+	// Percent is the cost as a percentage of the budget.
+	Percent float64
+	// Level is "ok", "warn" or "alert" depending on the percentage.
+	Level string
+}
+
+// Usage windows that can have budgets.
+const (
+	WindowSession = "session"
+	WindowDay     = "day"
+	WindowWeek    = "week"
+	WindowMonth   = "month"
+)
+
+func CCSessionUsage(opts UsageOptions) (Part, error) {
+	return usagePart(opts, WindowSession, sessionTranscripts, func(transcripts []transcript.Transcript, h CCHook) (map[string]transcript.Usage, string) {
+		return transcript.SessionUsage(transcripts, h.SessionID), h.SessionID
+	})
+}
+
+func CCHourUsage(opts UsageOptions) (Part, error) {
+	return windowUsagePart(opts, "hour", func() (time.Time, time.Time, time.Time) {
+		from, to := transcript.Hour(now())
+		return from, to, from
+	})
+}
+
+func CCDayUsage(opts UsageOptions) (Part, error) {
+	return windowUsagePart(opts, WindowDay, func() (time.Time, time.Time, time.Time) {
+		from, to := transcript.Day(now())
+		return from, to, from
+	})
+}
+
+// CCWeekUsage shows usage of the calendar week, or of the last 7 days if
+// calendar is false. Budget alerts fire once per calendar week either way.
+func CCWeekUsage(opts UsageOptions, calendar bool) (Part, error) {
+	return windowUsagePart(opts, WindowWeek, func() (time.Time, time.Time, time.Time) {
+		t := now()
+		week, _ := transcript.Week(t, settings.WeekStart)
+		if calendar {
+			from, to := transcript.Week(t, settings.WeekStart)
+			return from, to, week
+		}
+		return t.AddDate(0, 0, -7), t, week
+	})
+}
+
+func CCMonthUsage(opts UsageOptions) (Part, error) {
+	return windowUsagePart(opts, WindowMonth, func() (time.Time, time.Time, time.Time) {
+		from, to := transcript.Month(now())
+		return from, to, from
+	})
+}
+
+// windowUsagePart shows usage between from and to returned by window, with
+// start identifying the window for budget alerts.
+func windowUsagePart(opts UsageOptions, name string, window func() (from, to, start time.Time)) (Part, error) {
+	return usagePart(opts, name, allTranscripts, func(transcripts []transcript.Transcript, h CCHook) (map[string]transcript.Usage, string) {
+		from, to, start := window()
+		return transcript.DateUsage(transcripts, from, to), start.Format(time.RFC3339)
+	})
+}
+
+type BlockData struct {
+	Label  string
+	Tokens int
+	Cost   float64
+	Models map[string]transcript.Usage
+	// Unpriced lists models with usage but no known pricing.
+	Unpriced []string
+	Start    time.Time
+	End      time.Time
+	// Remaining is the time left until the block resets.
+	Remaining time.Duration
 }
 
-func CCDayUsage() Part {
-	return func(ctx context.Context, h CCHook) (string, error) {
+// CCBlockUsage shows usage of the active 5-hour billing block. It is hidden
+// when no block is active.
+func CCBlockUsage(opts UsageOptions) (Part, error) {
+	return Formatted(opts.Format, func(ctx context.Context, h CCHook) (BlockData, bool, error) {
 		transcripts, err := parsedTranscripts(ctx)
 		if err != nil {
-			return "", err
+			return BlockData{}, false, err
 		}
-		from := time.Now().Truncate(24 * time.Hour)
-		to := from.Add(24 * time.Hour)
-		usage := transcript.DateUsage(transcripts, from, to)
-		return formatUsage("day", usage), nil
-	}
+		if opts.Root != "" {
+			transcripts = transcript.FilterRoot(transcripts, opts.Root)
+		}
+		t := now()
+		b, ok := transcript.ActiveBlock(transcripts, t)
+		if !ok {
+			return BlockData{}, false, nil
+		}
+		usage := usageData(opts.Label, b.Usage)
+		return BlockData{
+			Label:     usage.Label,
+			Tokens:    usage.Tokens,
+			Cost:      usage.Cost,
+			Models:    usage.Models,
+			Unpriced:  usage.Unpriced,
+			Start:     b.Start.In(t.Location()),
+			End:       b.End.In(t.Location()),
+			Remaining: b.End.Sub(t),
+		}, true, nil
+	})
 }
 
-func CCWeekUsage() Part {
-	return func(ctx context.Context, h CCHook) (string, error) {
-		transcripts, err := parsedTranscripts(ctx)
+func allTranscripts(ctx context.Context, h CCHook) ([]transcript.Transcript, error) {
+	return parsedTranscripts(ctx)
+}
+
+func usagePart(
+	opts UsageOptions,
+	window string,
+	parse func(context.Context, CCHook) ([]transcript.Transcript, error),
+	usage func([]transcript.Transcript, CCHook) (map[string]transcript.Usage, string),
+) (Part, error) {
+	return Formatted(opts.Format, func(ctx context.Context, h CCHook) (UsageData, bool, error) {
+		transcripts, err := parse(ctx, h)
 		if err != nil {
-			return "", err
+			return UsageData{}, false, err
+		}
+		if opts.Root != "" {
+			transcripts = transcript.FilterRoot(transcripts, opts.Root)
 		}
-		to := time.Now()
-		from := to.Add(-7 * 24 * time.Hour)
-		usage := transcript.DateUsage(transcripts, from, to)
-		return formatUsage("week", usage), nil
+		models, key := usage(transcripts, h)
+		data := usageData(opts.Label, models)
+		if opts.Models > 0 {
+			data.Breakdown = modelBreakdown(models, opts.Models)
+		}
+		applyBudget(&data, window, opts.Root, key)
+		return data, true, nil
+	})
+}
+
+// usageData sums usage of models. Models are priced by their raw IDs and
+// merged by their normalized ones, so Bedrock and Vertex usage is listed
+// with the same model of the Anthropic API.
+func usageData(label string, usage map[string]transcript.Usage) UsageData {
+	data := UsageData{
+		Label:  label,
+		Models: make(map[string]transcript.Usage, len(usage)),
+		Level:  LevelOK,
 	}
+	for model, usage := range usage {
+		id := pricing.Normalize(model).ID
+		merged := data.Models[id]
+		merged.Merge(usage)
+		data.Models[id] = merged
+		data.Tokens += usage.Total()
+		data.WebSearchRequests += usage.WebSearchRequests
+		data.WebFetchRequests += usage.WebFetchRequests
+		cost, ok := pricing.Cost(model, usage)
+		data.Cost += cost
+		if !ok && usage.Total() > 0 && !slices.Contains(data.Unpriced, id) {
+			data.Unpriced = append(data.Unpriced, id)
+		}
+	}
+	slices.Sort(data.Unpriced)
+	return data
+}
+
+// ModelUsage is usage of models with the same short name, such as "opus".
+type ModelUsage struct {
+	Name   string
+	Tokens int
+	Cost   float64
 }
 
-func formatUsage(title string, usage map[string]transcript.Usage) string {
-	var combinedTokens int
-	var combinedPrice float64
+// modelBreakdown sums usage by model short name and returns the n most
+// expensive ones, with the rest summed as "other".
+func modelBreakdown(usage map[string]transcript.Usage, n int) []ModelUsage {
+	byName := make(map[string]*ModelUsage)
 	for model, usage := range usage {
-		combinedTokens += usage.Total()
-		price, ok := pricing.ModelPricing(model)
-		if ok {
-			combinedPrice += totalPrice(usage, price)
+		if usage.Total() == 0 {
+			continue
+		}
+		name := pricing.ShortName(model)
+		m := byName[name]
+		if m == nil {
+			m = &ModelUsage{Name: name}
+			byName[name] = m
 		}
+		cost, _ := pricing.Cost(model, usage)
+		m.Tokens += usage.Total()
+		m.Cost += cost
+	}
+	models := make([]ModelUsage, 0, len(byName))
+	for _, m := range byName {
+		models = append(models, *m)
+	}
+	slices.SortFunc(models, func(a, b ModelUsage) int {
+		return cmp.Or(cmp.Compare(b.Cost, a.Cost), cmp.Compare(b.Tokens, a.Tokens), strings.Compare(a.Name, b.Name))
+	})
+	if len(models) <= n {
+		return models
 	}
-	return fmt.Sprintf("%s %s%s",
-		title,
-		formatTokens(combinedTokens),
-		fmt.Sprintf(style.Green(" $%.1f"), combinedPrice))
+	other := ModelUsage{Name: "other"}
+	for _, m := range models[n:] {
+		other.Tokens += m.Tokens
+		other.Cost += m.Cost
+	}
+	return append(models[:n], other)
 }
 
 func formatTokens(tokens int) string {
@@ -101,10 +310,3 @@ func formatTokens(tokens int) string {
 	}
 	return fmt.Sprintf("%.1fBt", float64(tokens)/1000_000_000)
 }
-
-func totalPrice(usage transcript.Usage, price pricing.Pricing) float64 {
-	return float64(usage.InputTokens)*price.InputTokens +
-		float64(usage.OutputTokens)*price.OutputTokens +
-		float64(usage.CacheWriteTokens)*price.CacheWriteTokens +
-		float64(usage.CacheReadTokens)*price.CacheReadTokens
-}
@@ -0,0 +1,67 @@
+package parts_test
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/iskorotkov/cc-statusline/parts"
+)
+
+// usageEvent returns a transcript line with a message of Claude Sonnet 4 in
+// session s1 sent at the time in RFC 3339.
+func usageEvent(id, at string, inputTokens int) string {
+	return fmt.Sprintf(`{"sessionId":"s1","timestamp":%q,"message":{"id":%q,"model":"claude-sonnet-4-20250514","usage":{"input_tokens":%d}}}`+"\n", at, id, inputTokens)
+}
+
+// writeTranscripts writes a session transcript with lines to a new default
+// Claude config dir and returns its path. Parts parse it from scratch.
+func writeTranscripts(t *testing.T, lines ...string) string {
+	t.Helper()
+	home := t.TempDir()
+	t.Setenv("HOME", home)
+	t.Setenv("XDG_CACHE_HOME", filepath.Join(home, ".cache"))
+	t.Setenv("CLAUDE_CONFIG_DIR", filepath.Join(home, ".claude"))
+	dir := filepath.Join(home, ".claude", "projects", "-project")
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		t.Fatal(err)
+	}
+	path := filepath.Join(dir, "s1.jsonl")
+	if err := os.WriteFile(path, []byte(strings.Join(lines, "")), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	parts.ResetTranscripts(t)
+	return path
+}
+
+func TestSessionUsageModels(t *testing.T) {
+	var lines []string
+	for i, model := range []string{
+		"claude-opus-4-1-20250805",
+		"claude-opus-4-20250514",
+		"claude-sonnet-4-20250514",
+		"claude-3-5-haiku-20241022",
+		"claude-3-haiku-20240307",
+	} {
+		lines = append(lines, fmt.Sprintf(`{"sessionId":"s1","timestamp":"2025-01-01T10:00:00Z","message":{"id":"m%d","model":%q,"usage":{"input_tokens":100000}}}`+"\n", i, model))
+	}
+	path := writeTranscripts(t, lines...)
+
+	p, err := parts.New("api.session", map[string]any{
+		"models": 2,
+		"format": `{{range .Breakdown}}{{.Name}} {{tokens .Tokens}} {{money .Cost}}, {{end}}`,
+	})
+	if err != nil {
+		t.Fatalf("New() error: %v", err)
+	}
+	got, err := p(context.Background(), parts.CCHook{SessionID: "s1", TranscriptPath: path})
+	if err != nil {
+		t.Fatalf("part error: %v", err)
+	}
+	if want := "opus 200.0Kt $3.0, sonnet 100.0Kt $0.3, other 200.0Kt $0.1,"; got != want {
+		t.Errorf("part = %q, want %q", got, want)
+	}
+}
@@ -0,0 +1,91 @@
+package parts
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"strings"
+	"sync"
+)
+
+// PartError is returned by parts created with New when they fail.
+type PartError struct {
+	Name string
+	Err  error
+}
+
+func (e *PartError) Error() string {
+	return fmt.Sprintf("part %q: %v", e.Name, e.Err)
+}
+
+func (e *PartError) Unwrap() error {
+	return e.Err
+}
+
+// ErrorData is passed to the error marker template.
+type ErrorData struct {
+	Name  string
+	Group string
+	Error string
+}
+
+// running holds names of parts that are being rendered, so that parts that
+// miss the render deadline can be reported.
+var running sync.Map
+
+// named wraps errors and panics of p in a PartError with the part name.
+func named(name string, p Part) Part {
+	return func(ctx context.Context, h CCHook) (s string, err error) {
+		token := new(int)
+		running.Store(token, name)
+		defer running.Delete(token)
+		defer func() {
+			if p := recover(); p != nil {
+				s, err = "", &PartError{Name: name, Err: fmt.Errorf("panic: %v", p)}
+			}
+		}()
+		s, err = p(ctx, h)
+		if err != nil {
+			return "", &PartError{Name: name, Err: err}
+		}
+		return s, nil
+	}
+}
+
+// logFailure writes err to the debug log and returns the data for the error
+// marker.
+func logFailure(err error) ErrorData {
+	data := ErrorData{Error: err.Error()}
+	var pe *PartError
+	if errors.As(err, &pe) {
+		data.Name = pe.Name
+		data.Group, _, _ = strings.Cut(pe.Name, ".")
+	}
+	slog.Error("render part", "part", data.Name, "err", err)
+	return data
+}
+
+// logAbandoned writes parts that are still running to the debug log.
+func logAbandoned() {
+	running.Range(func(token, name any) bool {
+		if _, ok := running.LoadAndDelete(token); ok {
+			slog.Warn("part missed render deadline", "part", name)
+		}
+		return true
+	})
+}
+
+// failed logs err and returns the error marker to show instead of the part.
+func failed(err error) string {
+	data := logFailure(err)
+	if errorMarker == nil {
+		return ""
+	}
+	var b strings.Builder
+	if err := errorMarker.Execute(&b, data); err != nil {
+		slog.Error("render error marker", "part", data.Name, "err", err)
+		return ""
+	}
+	return strings.TrimSpace(b.String())
+}
@@ -0,0 +1,27 @@
+package parts
+
+import (
+	"testing"
+	"time"
+)
+
+// SetNow fixes the current time seen by parts until the test ends.
+func SetNow(t *testing.T, at time.Time) {
+	t.Helper()
+	clock = func() time.Time { return at }
+	t.Cleanup(func() {
+		clock = time.Now
+	})
+}
+
+// ResetTranscripts makes parts parse transcripts again, so that the test
+// sees its own fixtures.
+func ResetTranscripts(t *testing.T) {
+	t.Helper()
+	reset := func() {
+		parsedTranscripts = parseTranscriptsOnce()
+		sessionTranscripts = parseSessionOnce()
+	}
+	reset()
+	t.Cleanup(reset)
+}
@@ -0,0 +1,116 @@
+package parts
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"strings"
+	"text/template"
+	"time"
+
+	"github.com/iskorotkov/cc-statusline/style"
+)
+
+var formatFuncs = template.FuncMap{
+	"bold":      style.Bold,
+	"dim":       style.Dim,
+	"italic":    style.Italic,
+	"underline": style.Underline,
+	"blue":      style.Blue,
+	"red":       style.Red,
+	"green":     style.Green,
+	"yellow":    style.Yellow,
+	"level":     formatLevel,
+	"tokens":    formatTokens,
+	"money":     formatMoney,
+	"duration":  formatDuration,
+	"bar":       formatBar,
+	"limit": func(n int, s string) string {
+		return limit(s, n)
+	},
+	"hook": func() CCHook {
+		return CCHook{}
+	},
+}
+
+// Formatted creates a part that renders data returned by fn with a
+// text/template format. The template can call hook to access the CCHook.
+// fn returns false when the part has nothing to show.
+func Formatted[T any](format string, fn func(ctx context.Context, h CCHook) (T, bool, error)) (Part, error) {
+	t, err := template.New("format").Funcs(formatFuncs).Parse(format)
+	if err != nil {
+		return nil, fmt.Errorf("parse format: %w", err)
+	}
+	return func(ctx context.Context, h CCHook) (string, error) {
+		data, ok, err := fn(ctx, h)
+		if err != nil || !ok {
+			return "", err
+		}
+		t, err := t.Clone()
+		if err != nil {
+			return "", fmt.Errorf("clone format: %w", err)
+		}
+		t.Funcs(template.FuncMap{
+			"hook": func() CCHook {
+				return h
+			},
+		})
+		var b strings.Builder
+		if err := t.Execute(&b, data); err != nil {
+			return "", fmt.Errorf("execute format: %w", err)
+		}
+		return strings.TrimSpace(b.String()), nil
+	}, nil
+}
+
+func formatMoney(v float64) string {
+	return fmt.Sprintf("$%.1f", v)
+}
+
+// formatDuration formats d in hours and minutes, such as 2h05m or 45m.
+func formatDuration(d time.Duration) string {
+	d = d.Round(time.Minute)
+	if d < time.Hour {
+		return fmt.Sprintf("%dm", d/time.Minute)
+	}
+	return fmt.Sprintf("%dh%02dm", d/time.Hour, d%time.Hour/time.Minute)
+}
+
+// formatBar renders percent as a bar of width characters.
+func formatBar(width int, percent float64) string {
+	filled := int(math.Round(percent / 100 * float64(width)))
+	filled = min(max(filled, 0), width)
+	return strings.Repeat("█", filled) + strings.Repeat("░", width-filled)
+}
+
+// Levels of values compared to thresholds, used to pick a color.
+const (
+	LevelOK    = "ok"
+	LevelWarn  = "warn"
+	LevelAlert = "alert"
+)
+
+// level returns the level of v compared to the warn and alert thresholds.
+// Zero thresholds are ignored.
+func level(v, warn, alert float64) string {
+	switch {
+	case alert > 0 && v >= alert:
+		return LevelAlert
+	case warn > 0 && v >= warn:
+		return LevelWarn
+	default:
+		return LevelOK
+	}
+}
+
+// formatLevel colors s green, yellow or red depending on the level.
+func formatLevel(level, s string) string {
+	switch level {
+	case LevelAlert:
+		return style.Red(s)
+	case LevelWarn:
+		return style.Yellow(s)
+	default:
+		return style.Green(s)
+	}
+}
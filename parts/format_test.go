@@ -0,0 +1,81 @@
+package parts_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/iskorotkov/cc-statusline/parts"
+	"github.com/iskorotkov/cc-statusline/style"
+)
+
+func TestFormatted(t *testing.T) {
+	type data struct {
+		Name   string
+		Tokens int
+		Cost   float64
+		Left   time.Duration
+	}
+	tests := []struct {
+		format string
+		data   data
+		ok     bool
+		want   string
+	}{
+		{format: `{{.Name}} {{tokens .Tokens}} {{money .Cost}}`, data: data{Name: "day", Tokens: 1500, Cost: 2.25}, ok: true, want: "day 1.5Kt $2.2"},
+		{format: `{{(hook).Version}} {{.Name}}`, data: data{Name: "x"}, ok: true, want: "1.0.0 x"},
+		{format: `{{duration .Left}}`, data: data{Left: 2*time.Hour + 5*time.Minute + 40*time.Second}, ok: true, want: "2h06m"},
+		{format: `{{duration .Left}}`, data: data{Left: 45 * time.Minute}, ok: true, want: "45m"},
+		{format: `{{level "ok" .Name}}`, data: data{Name: "ok"}, ok: true, want: style.Green("ok")},
+		{format: `{{level "warn" .Name}}`, data: data{Name: "warn"}, ok: true, want: style.Yellow("warn")},
+		{format: `{{level "alert" .Name}}`, data: data{Name: "alert"}, ok: true, want: style.Red("alert")},
+		{format: `{{bar 4 .Cost}}`, data: data{Cost: 50}, ok: true, want: "██░░"},
+		{format: `{{bar 4 .Cost}}`, data: data{Cost: 130}, ok: true, want: "████"},
+		{format: `{{limit 5 .Name}}`, data: data{Name: "abcdefghij"}, ok: true, want: "ab..."},
+		{format: `  {{.Name}}  `, data: data{Name: "trimmed"}, ok: true, want: "trimmed"},
+		{format: `{{.Name}}`, data: data{Name: "hidden"}, ok: false, want: ""},
+	}
+	for _, tt := range tests {
+		p, err := parts.Formatted(tt.format, func(ctx context.Context, h parts.CCHook) (data, bool, error) {
+			return tt.data, tt.ok, nil
+		})
+		if err != nil {
+			t.Fatalf("Formatted(%q) error: %v", tt.format, err)
+		}
+		got, err := p(context.Background(), parts.CCHook{Version: "1.0.0"})
+		if err != nil {
+			t.Errorf("format %q error: %v", tt.format, err)
+		}
+		if got != tt.want {
+			t.Errorf("format %q = %q, want %q", tt.format, got, tt.want)
+		}
+	}
+}
+
+func TestFormattedErrors(t *testing.T) {
+	if _, err := parts.Formatted(`{{.Name`, func(ctx context.Context, h parts.CCHook) (struct{}, bool, error) {
+		return struct{}{}, true, nil
+	}); err == nil {
+		t.Error("expected parse error")
+	}
+	p, err := parts.Formatted(`{{.Missing}}`, func(ctx context.Context, h parts.CCHook) (struct{}, bool, error) {
+		return struct{}{}, true, nil
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := p(context.Background(), parts.CCHook{}); err == nil {
+		t.Error("expected execute error")
+	}
+	want := errors.New("data error")
+	p, err = parts.Formatted(`x`, func(ctx context.Context, h parts.CCHook) (struct{}, bool, error) {
+		return struct{}{}, true, want
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := p(context.Background(), parts.CCHook{}); !errors.Is(err, want) {
+		t.Errorf("expected data error, got %v", err)
+	}
+}
@@ -2,35 +2,39 @@ package parts
 
 import (
 	"context"
-	"fmt"
 	"regexp"
 	"strings"
-	"sync"
 
+	"github.com/iskorotkov/cc-statusline/cache"
 	"github.com/iskorotkov/cc-statusline/shell"
-	"github.com/iskorotkov/cc-statusline/style"
 )
 
 var ghIssueCodeRegex = regexp.MustCompile(`\d+`)
 
-var ghPRViewJSON = func() func(ctx context.Context) (GHPR, error) {
-	var pr GHPR
-	var err error
-	var once sync.Once
-	return func(ctx context.Context) (GHPR, error) {
-		once.Do(func() {
-			pr, err = shell.JSON[GHPR](
-				ctx,
-				"gh",
-				"pr",
-				"view",
-				"--json",
-				"number,url,title,mergeable,additions,deletions,changedFiles,baseRefName,headRefName",
-			)
-		})
-		return pr, err
-	}
-}()
+var ghPRViewJSON = cached(&cache.Source[GHPR]{
+	Name: "gh_pr",
+	Key: func(ctx context.Context) (string, error) {
+		dir, err := dirKey(ctx)
+		if err != nil {
+			return "", err
+		}
+		branch, err := gitBranchShowCurrent(ctx)
+		if err != nil {
+			return "", err
+		}
+		return dir + "\x00" + branch, nil
+	},
+	Fetch: func(ctx context.Context) (GHPR, error) {
+		return shell.JSON[GHPR](
+			ctx,
+			"gh",
+			"pr",
+			"view",
+			"--json",
+			"number,url,title,mergeable,additions,deletions,changedFiles,baseRefName,headRefName",
+		)
+	},
+})
 
 type GHPR struct {
 	Number       int    `json:"number"`
@@ -44,68 +48,54 @@ type GHPR struct {
 	HeadRefName  string `json:"headRefName"`
 }
 
-func GHPRNumber() Part {
-	return func(ctx context.Context, h CCHook) (string, error) {
-		pr, _ := ghPRViewJSON(ctx)
-		if pr == (GHPR{}) {
-			return "", nil
-		}
-		return fmt.Sprintf(style.Bold("#%d"), pr.Number), nil
-	}
+// TaskData describes an issue or task referenced by the branch or PR.
+type TaskData struct {
+	Code string
+	URL  string
 }
 
-func GHPRTitle() Part {
-	return func(ctx context.Context, h CCHook) (string, error) {
-		pr, _ := ghPRViewJSON(ctx)
-		if pr == (GHPR{}) {
-			return "", nil
-		}
-		return style.Italic(limit(pr.Title, 60)), nil
-	}
+func GHPRNumber(format string) (Part, error) {
+	return Formatted(format, ghPRData)
 }
 
-func GHPRStats() Part {
-	return func(ctx context.Context, h CCHook) (string, error) {
-		pr, _ := ghPRViewJSON(ctx)
-		if pr == (GHPR{}) {
-			return "", nil
-		}
-		mergeStatus := style.Red("NM")
-		if pr.Mergeable == "MERGEABLE" {
-			mergeStatus = style.Green("M")
-		}
-		return fmt.Sprintf("%s %s ~%dF %s",
-			style.Green(fmt.Sprintf("+%dL", pr.Additions)),
-			style.Red(fmt.Sprintf("-%dL", pr.Deletions)),
-			pr.ChangedFiles,
-			mergeStatus), nil
-	}
+func GHPRTitle(maxLen int, format string) (Part, error) {
+	return Formatted(format, func(ctx context.Context, h CCHook) (GHPR, bool, error) {
+		pr, ok, err := ghPRData(ctx, h)
+		pr.Title = limit(pr.Title, maxLen)
+		return pr, ok, err
+	})
 }
 
-func GHPRURL() Part {
-	return func(ctx context.Context, h CCHook) (string, error) {
-		pr, _ := ghPRViewJSON(ctx)
-		if pr == (GHPR{}) {
-			return "", nil
-		}
-		return style.Underline(pr.URL), nil
-	}
+func GHPRStats(format string) (Part, error) {
+	return Formatted(format, ghPRData)
 }
 
-func GHIssueURL() Part {
-	return func(ctx context.Context, h CCHook) (string, error) {
+func GHPRURL(format string) (Part, error) {
+	return Formatted(format, ghPRData)
+}
+
+func GHIssueURL(format string) (Part, error) {
+	return Formatted(format, func(ctx context.Context, h CCHook) (TaskData, bool, error) {
 		origin, _ := gitRemoteGetURLOrigin(ctx)
 		branch, _ := gitBranchShowCurrent(ctx)
 		pr, _ := ghPRViewJSON(ctx)
 		if origin == "" {
-			return "", nil
+			return TaskData{}, false, nil
 		}
 		code, ok := extractGHIssueCode(branch, pr.HeadRefName, pr.Title)
 		if !ok {
-			return "", nil
+			return TaskData{}, false, nil
 		}
-		return style.Underline(strings.TrimSuffix(origin, ".git") + "/issues/" + code), nil
-	}
+		return TaskData{
+			Code: code,
+			URL:  strings.TrimSuffix(origin, ".git") + "/issues/" + code,
+		}, true, nil
+	})
+}
+
+func ghPRData(ctx context.Context, h CCHook) (GHPR, bool, error) {
+	pr, _ := ghPRViewJSON(ctx)
+	return pr, pr != (GHPR{}), nil
 }
 
 func extractGHIssueCode(s ...string) (string, bool) {
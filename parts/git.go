@@ -2,131 +2,128 @@ package parts
 
 import (
 	"context"
-	"fmt"
 	"strconv"
 	"strings"
-	"sync"
 
+	"github.com/iskorotkov/cc-statusline/cache"
 	"github.com/iskorotkov/cc-statusline/shell"
-	"github.com/iskorotkov/cc-statusline/style"
 )
 
-var gitRemoteGetURLOrigin = func() func(ctx context.Context) (string, error) {
-	var remote string
-	var err error
-	var once sync.Once
-	return func(ctx context.Context) (string, error) {
-		once.Do(func() {
-			remote, err = shell.String(ctx, "git", "ls-remote", "--get-url", "origin")
-		})
-		return remote, err
-	}
-}()
+var gitRemoteGetURLOrigin = cached(&cache.Source[string]{
+	Name: "git_remote",
+	Key:  dirKey,
+	Fetch: func(ctx context.Context) (string, error) {
+		return shell.String(ctx, "git", "ls-remote", "--get-url", "origin")
+	},
+})
 
-var gitBranchShowCurrent = func() func(ctx context.Context) (string, error) {
-	var branch string
-	var err error
-	var once sync.Once
-	return func(ctx context.Context) (string, error) {
-		once.Do(func() {
-			branch, err = shell.String(ctx, "git", "branch", "--show-current")
-		})
-		return branch, err
-	}
-}()
+var gitBranchShowCurrent = cached(&cache.Source[string]{
+	Name: "git_branch",
+	Key:  dirKey,
+	Fetch: func(ctx context.Context) (string, error) {
+		return shell.String(ctx, "git", "branch", "--show-current")
+	},
+})
 
-var gitStatusPorcelain = func() func(ctx context.Context) (string, error) {
-	var status string
-	var err error
-	var once sync.Once
-	return func(ctx context.Context) (string, error) {
-		once.Do(func() {
-			status, err = shell.String(ctx, "git", "status", "--porcelain")
-		})
-		return status, err
-	}
-}()
+var gitStatusPorcelain = cached(&cache.Source[string]{
+	Name: "git_status",
+	Key:  dirKey,
+	Fetch: func(ctx context.Context) (string, error) {
+		return shell.String(ctx, "git", "status", "--porcelain")
+	},
+})
 
-var gitDiffNumstat = func() func(ctx context.Context) (string, error) {
-	var diff string
-	var err error
-	var once sync.Once
-	return func(ctx context.Context) (string, error) {
-		once.Do(func() {
-			diff, err = shell.String(ctx, "git", "diff", "HEAD", "--numstat")
-		})
-		return diff, err
-	}
-}()
+var gitDiffNumstat = cached(&cache.Source[string]{
+	Name: "git_diff",
+	Key:  dirKey,
+	Fetch: func(ctx context.Context) (string, error) {
+		return shell.String(ctx, "git", "diff", "HEAD", "--numstat")
+	},
+})
 
-func GitRemoteOrigin() Part {
-	return func(ctx context.Context, h CCHook) (string, error) {
+type GitRemoteData struct {
+	URL string
+}
+
+type GitBranchData struct {
+	Branch string
+}
+
+type GitStatusData struct {
+	Files []GitFileStatus
+}
+
+type GitFileStatus struct {
+	Status string
+	Count  int
+}
+
+type GitDiffData struct {
+	Added   int
+	Removed int
+}
+
+func GitRemoteOrigin(maxLen int, format string) (Part, error) {
+	return Formatted(format, func(ctx context.Context, h CCHook) (GitRemoteData, bool, error) {
 		remote, _ := gitRemoteGetURLOrigin(ctx)
-		if remote != "" {
-			remote = strings.TrimSuffix(remote, ".git")
-			return style.Underline(limit(remote, 60)), nil
+		if remote == "" {
+			return GitRemoteData{}, false, nil
 		}
-		return "", nil
-	}
+		remote = strings.TrimSuffix(remote, ".git")
+		return GitRemoteData{URL: limit(remote, maxLen)}, true, nil
+	})
 }
 
-func GitBranch() Part {
-	return func(ctx context.Context, h CCHook) (string, error) {
+func GitBranch(maxLen int, format string) (Part, error) {
+	return Formatted(format, func(ctx context.Context, h CCHook) (GitBranchData, bool, error) {
 		branch, _ := gitBranchShowCurrent(ctx)
-		if branch != "" {
-			return style.Italic(limit(branch, 60)), nil
+		if branch == "" {
+			return GitBranchData{}, false, nil
 		}
-		return "", nil
-	}
+		return GitBranchData{Branch: limit(branch, maxLen)}, true, nil
+	})
 }
 
-func GitStatus() Part {
-	return func(ctx context.Context, h CCHook) (string, error) {
+func GitStatus(format string) (Part, error) {
+	return Formatted(format, func(ctx context.Context, h CCHook) (GitStatusData, bool, error) {
 		files, _ := gitStatusPorcelain(ctx)
 		if len(files) == 0 {
-			return "", nil
+			return GitStatusData{}, false, nil
 		}
 		fileCount := count(files)
 		if len(fileCount) == 0 {
-			return "", nil
+			return GitStatusData{}, false, nil
 		}
-		parts := make([]string, len(fileCount))
+		data := GitStatusData{Files: make([]GitFileStatus, len(fileCount))}
 		for i, p := range fileCount {
-			parts[i] = fmt.Sprintf("%s:%d", p.k, p.v)
+			data.Files[i] = GitFileStatus{Status: p.k, Count: p.v}
 		}
-		return strings.Join(parts, " "), nil
-	}
+		return data, true, nil
+	})
 }
 
-func GitDiffStats() Part {
-	return func(ctx context.Context, h CCHook) (string, error) {
+func GitDiffStats(format string) (Part, error) {
+	return Formatted(format, func(ctx context.Context, h CCHook) (GitDiffData, bool, error) {
 		diff, _ := gitDiffNumstat(ctx)
 		if diff == "" {
-			return "", nil
+			return GitDiffData{}, false, nil
 		}
-		var added, removed int
+		var data GitDiffData
 		for line := range strings.Lines(diff) {
 			fields := strings.Fields(line)
 			if len(fields) < 2 {
 				continue
 			}
 			if a, err := strconv.Atoi(fields[0]); err == nil {
-				added += a
+				data.Added += a
 			}
 			if r, err := strconv.Atoi(fields[1]); err == nil {
-				removed += r
+				data.Removed += r
 			}
 		}
-		if added == 0 && removed == 0 {
-			return "", nil
-		}
-		parts := make([]string, 0, 2)
-		if added > 0 {
-			parts = append(parts, style.Green(fmt.Sprintf("+%dL", added)))
-		}
-		if removed > 0 {
-			parts = append(parts, style.Red(fmt.Sprintf("-%dL", removed)))
+		if data.Added == 0 && data.Removed == 0 {
+			return GitDiffData{}, false, nil
 		}
-		return strings.Join(parts, " "), nil
-	}
+		return data, true, nil
+	})
 }
@@ -4,26 +4,27 @@ import (
 	"context"
 	"os"
 	"regexp"
-
-	"github.com/iskorotkov/cc-statusline/style"
 )
 
 var jiraCodeRegex = regexp.MustCompile(`\w+\-\d+`)
 
-func JiraURL() Part {
-	return func(ctx context.Context, h CCHook) (string, error) {
+func JiraURL(server, format string) (Part, error) {
+	return Formatted(format, func(ctx context.Context, h CCHook) (TaskData, bool, error) {
 		branch, _ := gitBranchShowCurrent(ctx)
 		pr, _ := ghPRViewJSON(ctx)
-		url := os.Getenv("CC_JIRA_URL")
+		url := server
+		if url == "" {
+			url = os.Getenv("CC_JIRA_URL")
+		}
 		if url == "" {
-			return "", nil
+			return TaskData{}, false, nil
 		}
 		code, ok := extractJiraCode(branch, pr.HeadRefName, pr.Title)
 		if !ok {
-			return "", nil
+			return TaskData{}, false, nil
 		}
-		return style.Underline(url + "/" + code), nil
-	}
+		return TaskData{Code: code, URL: url + "/" + code}, true, nil
+	})
 }
 
 func extractJiraCode(s ...string) (string, bool) {
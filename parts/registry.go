@@ -0,0 +1,657 @@
+package parts
+
+import (
+	"fmt"
+	"maps"
+	"math"
+	"slices"
+	"time"
+)
+
+type ParamType string
+
+const (
+	ParamString   ParamType = "string"
+	ParamInt      ParamType = "int"
+	ParamFloat    ParamType = "float"
+	ParamBool     ParamType = "bool"
+	ParamDuration ParamType = "duration"
+)
+
+type Param struct {
+	Name        string
+	Type        ParamType
+	Default     any
+	Description string
+}
+
+// Definition describes a part that can be referenced by name from the config.
+type Definition struct {
+	Name        string
+	Description string
+	// Data is a zero value of the data passed to the part format.
+	Data   any
+	Params []Param
+	New    func(p Params) (Part, error)
+}
+
+// Params holds validated part parameters. Every declared parameter is set,
+// either from the config or from its default value.
+type Params map[string]any
+
+func (p Params) String(name string) string {
+	v, _ := p[name].(string)
+	return v
+}
+
+func (p Params) Int(name string) int {
+	v, _ := p[name].(int)
+	return v
+}
+
+func (p Params) Float(name string) float64 {
+	v, _ := p[name].(float64)
+	return v
+}
+
+func (p Params) Bool(name string) bool {
+	v, _ := p[name].(bool)
+	return v
+}
+
+func (p Params) Duration(name string) time.Duration {
+	v, _ := p[name].(time.Duration)
+	return v
+}
+
+var definitions = []Definition{
+	{
+		Name:        "cc.version",
+		Description: "Claude Code version",
+		Data:        CCHook{},
+		Params:      []Param{formatParam(`{{dim (printf "v%s" .Version)}}`)},
+		New:         formatOnly(CCVersion),
+	},
+	{
+		Name:        "cc.model",
+		Description: "Display name of the current model",
+		Data:        CCHook{},
+		Params:      []Param{formatParam(`{{bold .Model.DisplayName}}`)},
+		New:         formatOnly(CCModel),
+	},
+	{
+		Name:        "cc.output_style",
+		Description: "Name of the current output style",
+		Data:        CCHook{},
+		Params:      []Param{formatParam(`{{dim .OutputStyle.Name}}`)},
+		New:         formatOnly(CCOutputStyle),
+	},
+	{
+		Name:        "cc.dir",
+		Description: "Current dir relative to the parent of the project dir",
+		Data:        CCDirData{},
+		Params: []Param{
+			limitParam(20),
+			formatParam(`{{italic .Dir}}`),
+		},
+		New: func(p Params) (Part, error) {
+			return CCDir(p.Int("limit"), p.String("format"))
+		},
+	},
+	{
+		Name:        "cc.stats",
+		Description: "Lines added and removed, API duration and cost of the session",
+		Data:        CCStatsData{},
+		Params: []Param{formatParam(
+			`{{green (printf "+%dL" .LinesAdded)}} {{red (printf "-%dL" .LinesRemoved)}} ` +
+				`{{printf "%.1fm" .APIDuration.Minutes}} {{green (money .Cost)}}`,
+		)},
+		New: formatOnly(CCStats),
+	},
+	{
+		Name:        "cc.context_badge",
+		Description: "Badge shown when the context exceeds 200K tokens",
+		Data:        CCHook{},
+		Params:      []Param{formatParam(`{{bold "200K+"}}`)},
+		New:         formatOnly(CC200KContextBadge),
+	},
+	{
+		Name:        "cc.context",
+		Description: "Context window usage of the session",
+		Data:        ContextData{},
+		Params: []Param{
+			{
+				Name:        "window",
+				Type:        ParamInt,
+				Default:     0,
+				Description: "Context window size in tokens, 0 to detect it from the model",
+			},
+			{
+				Name:        "warn",
+				Type:        ParamFloat,
+				Default:     60.0,
+				Description: "Percentage shown as a warning, 0 disables it",
+			},
+			{
+				Name:        "alert",
+				Type:        ParamFloat,
+				Default:     75.0,
+				Description: "Percentage shown as an alert as auto-compaction approaches, 0 disables it",
+			},
+			formatParam(`{{level .Level (printf "%s %.0f%%" (bar 10 .Percent) .Percent)}} {{dim (printf "%s/%s" (tokens .Tokens) (tokens .Window))}}`),
+		},
+		New: func(p Params) (Part, error) {
+			return CCContext(ContextOptions{
+				Window: p.Int("window"),
+				Warn:   p.Float("warn"),
+				Alert:  p.Float("alert"),
+				Format: p.String("format"),
+			})
+		},
+	},
+	{
+		Name:        "cc.transcript_path",
+		Description: "Path to the session transcript",
+		Data:        CCHook{},
+		Params:      []Param{formatParam(`{{dim .TranscriptPath}}`)},
+		New:         formatOnly(CCTranscriptPath),
+	},
+	{
+		Name:        "api.session",
+		Description: "Tokens and cost of the current session",
+		Data:        UsageData{},
+		Params:      usageParams("session"),
+		New: func(p Params) (Part, error) {
+			return CCSessionUsage(usageOptions(p))
+		},
+	},
+	{
+		Name:        "api.hour",
+		Description: "Tokens and cost of the current calendar hour across all sessions",
+		Data:        UsageData{},
+		Params:      usageParams("hour"),
+		New: func(p Params) (Part, error) {
+			return CCHourUsage(usageOptions(p))
+		},
+	},
+	{
+		Name:        "api.day",
+		Description: "Tokens and cost of the current calendar day across all sessions",
+		Data:        UsageData{},
+		Params:      usageParams("day"),
+		New: func(p Params) (Part, error) {
+			return CCDayUsage(usageOptions(p))
+		},
+	},
+	{
+		Name:        "api.week",
+		Description: "Tokens and cost of the last 7 days or calendar week across all sessions",
+		Data:        UsageData{},
+		Params: append(usageParams("week"), Param{
+			Name:        "calendar",
+			Type:        ParamBool,
+			Default:     false,
+			Description: "Count the calendar week instead of the last 7 days",
+		}),
+		New: func(p Params) (Part, error) {
+			return CCWeekUsage(usageOptions(p), p.Bool("calendar"))
+		},
+	},
+	{
+		Name:        "api.month",
+		Description: "Tokens and cost of the current calendar month across all sessions",
+		Data:        UsageData{},
+		Params:      usageParams("month"),
+		New: func(p Params) (Part, error) {
+			return CCMonthUsage(usageOptions(p))
+		},
+	},
+	{
+		Name:        "api.project",
+		Description: "Tokens and cost of the current project next to the cost of all projects",
+		Data:        ProjectData{},
+		Params: []Param{
+			{
+				Name:        "label",
+				Type:        ParamString,
+				Default:     "project",
+				Description: "Label shown before the usage",
+			},
+			{
+				Name:        "period",
+				Type:        ParamString,
+				Default:     WindowDay,
+				Description: `"day", "week" for the last 7 days, or "month"`,
+			},
+			rootParam(),
+			formatParam(`{{.Label}} {{tokens .Tokens}} {{green (money .Cost)}}` + unpricedMarker + `{{dim (printf "/%s" (money .TotalCost))}}`),
+		},
+		New: func(p Params) (Part, error) {
+			return CCProjectUsage(ProjectOptions{
+				Label:  p.String("label"),
+				Period: p.String("period"),
+				Root:   p.String("root"),
+				Format: p.String("format"),
+			})
+		},
+	},
+	{
+		Name:        "api.branch",
+		Description: "Tokens and cost of all sessions on the current git branch",
+		Data:        BranchData{},
+		Params: []Param{
+			{
+				Name:        "label",
+				Type:        ParamString,
+				Default:     "claude",
+				Description: "Label shown before the usage",
+			},
+			rootParam(),
+			formatParam(`{{dim .Label}} {{green (money .Cost)}}` + unpricedMarker),
+		},
+		New: func(p Params) (Part, error) {
+			return CCBranchUsage(usageOptions(p))
+		},
+	},
+	{
+		Name:        "api.cache",
+		Description: "Share of input read from the prompt cache and the cost it saved",
+		Data:        CacheData{},
+		Params: []Param{
+			{
+				Name:        "label",
+				Type:        ParamString,
+				Default:     "cache",
+				Description: "Label shown before the cache usage",
+			},
+			{
+				Name:        "period",
+				Type:        ParamString,
+				Default:     WindowSession,
+				Description: `"session" or "day"`,
+			},
+			rootParam(),
+			formatParam(
+				`{{.Label}} {{printf "%.0f%%" .Ratio}} ` +
+					`{{$saved := printf "%s saved" (money .Savings)}}{{if lt .Savings 0.0}}{{red $saved}}{{else}}{{green $saved}}{{end}}`,
+			),
+		},
+		New: func(p Params) (Part, error) {
+			return CCCacheUsage(CacheOptions{
+				Label:  p.String("label"),
+				Period: p.String("period"),
+				Root:   p.String("root"),
+				Format: p.String("format"),
+			})
+		},
+	},
+	{
+		Name:        "api.block",
+		Description: "Tokens, cost and time left of the active 5-hour billing block",
+		Data:        BlockData{},
+		Params: []Param{
+			{
+				Name:        "label",
+				Type:        ParamString,
+				Default:     "block",
+				Description: "Label shown before the usage",
+			},
+			rootParam(),
+			formatParam(`{{.Label}} {{tokens .Tokens}} {{green (money .Cost)}}` + unpricedMarker + ` {{dim (printf "%s left" (duration .Remaining))}}`),
+		},
+		New: func(p Params) (Part, error) {
+			return CCBlockUsage(usageOptions(p))
+		},
+	},
+	{
+		Name:        "api.burn_rate",
+		Description: "Tokens per minute and cost per hour, and the cost projected to the end of the block or day",
+		Data:        BurnRateData{},
+		Params: []Param{
+			{
+				Name:        "window",
+				Type:        ParamDuration,
+				Default:     30 * time.Minute,
+				Description: "Trailing window the rate is computed over",
+			},
+			{
+				Name:        "period",
+				Type:        ParamString,
+				Default:     PeriodBlock,
+				Description: `Period the cost is projected to the end of, "block" or "day"`,
+			},
+			{
+				Name:        "warn",
+				Type:        ParamFloat,
+				Default:     5.0,
+				Description: "Cost per hour shown as a warning, 0 disables it",
+			},
+			{
+				Name:        "alert",
+				Type:        ParamFloat,
+				Default:     10.0,
+				Description: "Cost per hour shown as an alert, 0 disables it",
+			},
+			rootParam(),
+			formatParam(
+				`{{tokens .TokensPerMinute}}/m {{level .Level (printf "%s/h" (money .CostPerHour))}} ` +
+					`{{dim (printf "%s ~" .Period)}}{{level .Level (money .Projected)}}`,
+			),
+		},
+		New: func(p Params) (Part, error) {
+			return CCBurnRate(BurnRateOptions{
+				Window: p.Duration("window"),
+				Period: p.String("period"),
+				Warn:   p.Float("warn"),
+				Alert:  p.Float("alert"),
+				Root:   p.String("root"),
+				Format: p.String("format"),
+			})
+		},
+	},
+	{
+		Name:        "git.remote",
+		Description: "URL of the origin remote",
+		Data:        GitRemoteData{},
+		Params: []Param{
+			limitParam(60),
+			formatParam(`{{underline .URL}}`),
+		},
+		New: func(p Params) (Part, error) {
+			return GitRemoteOrigin(p.Int("limit"), p.String("format"))
+		},
+	},
+	{
+		Name:        "git.branch",
+		Description: "Current branch",
+		Data:        GitBranchData{},
+		Params: []Param{
+			limitParam(60),
+			formatParam(`{{italic .Branch}}`),
+		},
+		New: func(p Params) (Part, error) {
+			return GitBranch(p.Int("limit"), p.String("format"))
+		},
+	},
+	{
+		Name:        "git.status",
+		Description: "Number of changed files by status",
+		Data:        GitStatusData{},
+		Params: []Param{formatParam(
+			`{{range $i, $f := .Files}}{{if $i}} {{end}}{{$f.Status}}:{{$f.Count}}{{end}}`,
+		)},
+		New: formatOnly(GitStatus),
+	},
+	{
+		Name:        "git.diff",
+		Description: "Lines added and removed since HEAD",
+		Data:        GitDiffData{},
+		Params: []Param{formatParam(
+			`{{if .Added}}{{green (printf "+%dL" .Added)}}{{end}} ` +
+				`{{if .Removed}}{{red (printf "-%dL" .Removed)}}{{end}}`,
+		)},
+		New: formatOnly(GitDiffStats),
+	},
+	{
+		Name:        "gh.pr.number",
+		Description: "Number of the PR for the current branch",
+		Data:        GHPR{},
+		Params:      []Param{formatParam(`{{bold (printf "#%d" .Number)}}`)},
+		New:         formatOnly(GHPRNumber),
+	},
+	{
+		Name:        "gh.pr.title",
+		Description: "Title of the PR for the current branch",
+		Data:        GHPR{},
+		Params: []Param{
+			limitParam(60),
+			formatParam(`{{italic .Title}}`),
+		},
+		New: func(p Params) (Part, error) {
+			return GHPRTitle(p.Int("limit"), p.String("format"))
+		},
+	},
+	{
+		Name:        "gh.pr.stats",
+		Description: "Lines added and removed, changed files and mergeability of the PR",
+		Data:        GHPR{},
+		Params: []Param{formatParam(
+			`{{green (printf "+%dL" .Additions)}} {{red (printf "-%dL" .Deletions)}} ~{{.ChangedFiles}}F ` +
+				`{{if eq .Mergeable "MERGEABLE"}}{{green "M"}}{{else}}{{red "NM"}}{{end}}`,
+		)},
+		New: formatOnly(GHPRStats),
+	},
+	{
+		Name:        "gh.pr.url",
+		Description: "URL of the PR for the current branch",
+		Data:        GHPR{},
+		Params:      []Param{formatParam(`{{underline .URL}}`)},
+		New:         formatOnly(GHPRURL),
+	},
+	{
+		Name:        "gh.issue.url",
+		Description: "URL of the GitHub issue referenced by the branch or PR",
+		Data:        TaskData{},
+		Params:      []Param{formatParam(`{{underline .URL}}`)},
+		New:         formatOnly(GHIssueURL),
+	},
+	{
+		Name:        "jira.url",
+		Description: "URL of the Jira issue referenced by the branch or PR",
+		Data:        TaskData{},
+		Params: []Param{
+			{
+				Name:        "server",
+				Type:        ParamString,
+				Default:     "",
+				Description: "Jira browse URL, defaults to $CC_JIRA_URL",
+			},
+			formatParam(`{{underline .URL}}`),
+		},
+		New: func(p Params) (Part, error) {
+			return JiraURL(p.String("server"), p.String("format"))
+		},
+	},
+	{
+		Name:        "task.url",
+		Description: "URL of the task referenced by the branch",
+		Data:        TaskData{},
+		Params: []Param{
+			{
+				Name:        "server",
+				Type:        ParamString,
+				Default:     "",
+				Description: "Task server URL, defaults to $CC_TASK_SERVER",
+			},
+			formatParam(`{{underline .URL}}`),
+		},
+		New: func(p Params) (Part, error) {
+			return TaskURL(p.String("server"), p.String("format"))
+		},
+	},
+	{
+		Name:        "text",
+		Description: "Fixed text, rendered as a template over the hook",
+		Data:        CCHook{},
+		Params: []Param{{
+			Name:        "text",
+			Type:        ParamString,
+			Default:     "",
+			Description: "Text to show",
+		}},
+		New: func(p Params) (Part, error) {
+			return Formatted(p.String("text"), hookData)
+		},
+	},
+}
+
+// Definitions returns all registered parts.
+func Definitions() []Definition {
+	return slices.Clone(definitions)
+}
+
+func Lookup(name string) (Definition, bool) {
+	i := slices.IndexFunc(definitions, func(d Definition) bool {
+		return d.Name == name
+	})
+	if i < 0 {
+		return Definition{}, false
+	}
+	return definitions[i], true
+}
+
+// New creates the part registered as name. Params are validated against
+// the part definition, and missing params get their default values.
+func New(name string, params map[string]any) (Part, error) {
+	d, ok := Lookup(name)
+	if !ok {
+		return nil, fmt.Errorf("unknown part %q", name)
+	}
+	p, err := d.params(params)
+	if err != nil {
+		return nil, fmt.Errorf("part %q: %w", name, err)
+	}
+	part, err := d.New(p)
+	if err != nil {
+		return nil, fmt.Errorf("part %q: %w", name, err)
+	}
+	return named(name, part), nil
+}
+
+func (d Definition) params(raw map[string]any) (Params, error) {
+	for _, name := range slices.Sorted(maps.Keys(raw)) {
+		if !slices.ContainsFunc(d.Params, func(p Param) bool { return p.Name == name }) {
+			return nil, fmt.Errorf("unknown param %q", name)
+		}
+	}
+	params := make(Params, len(d.Params))
+	for _, p := range d.Params {
+		v, ok := raw[p.Name]
+		if !ok {
+			params[p.Name] = p.Default
+			continue
+		}
+		v, err := p.convert(v)
+		if err != nil {
+			return nil, fmt.Errorf("param %q: %w", p.Name, err)
+		}
+		params[p.Name] = v
+	}
+	return params, nil
+}
+
+func (p Param) convert(v any) (any, error) {
+	switch p.Type {
+	case ParamString:
+		if s, ok := v.(string); ok {
+			return s, nil
+		}
+	case ParamInt:
+		switch n := v.(type) {
+		case int:
+			return n, nil
+		case int64:
+			return int(n), nil
+		case float64:
+			if n == math.Trunc(n) {
+				return int(n), nil
+			}
+		}
+	case ParamFloat:
+		switch n := v.(type) {
+		case int:
+			return float64(n), nil
+		case int64:
+			return float64(n), nil
+		case float64:
+			return n, nil
+		}
+	case ParamBool:
+		if b, ok := v.(bool); ok {
+			return b, nil
+		}
+	case ParamDuration:
+		if s, ok := v.(string); ok {
+			d, err := time.ParseDuration(s)
+			if err != nil {
+				return nil, err
+			}
+			return d, nil
+		}
+	}
+	return nil, fmt.Errorf("expected %s, got %T", p.Type, v)
+}
+
+func formatOnly(f func(format string) (Part, error)) func(Params) (Part, error) {
+	return func(p Params) (Part, error) {
+		return f(p.String("format"))
+	}
+}
+
+func formatParam(format string) Param {
+	return Param{
+		Name:        "format",
+		Type:        ParamString,
+		Default:     format,
+		Description: "text/template format over the part data",
+	}
+}
+
+func usageFormatParam() Param {
+	return formatParam(
+		`{{.Label}} {{tokens .Tokens}} ` +
+			`{{if .Budget}}{{level .Level (printf "%s/$%.0f" (money .Cost) .Budget)}}{{else}}{{green (money .Cost)}}{{end}}` +
+			unpricedMarker +
+			`{{range .Breakdown}} {{dim (printf "%s %s %s" .Name (tokens .Tokens) (money .Cost))}}{{end}}`,
+	)
+}
+
+// unpricedMarker marks costs that estimate or leave out models with unknown
+// pricing.
+const unpricedMarker = `{{if .Unpriced}}{{yellow "?"}}{{end}}`
+
+func limitParam(n int) Param {
+	return Param{
+		Name:        "limit",
+		Type:        ParamInt,
+		Default:     n,
+		Description: "Max length, 0 disables truncation",
+	}
+}
+
+func usageParams(label string) []Param {
+	return []Param{
+		{
+			Name:        "label",
+			Type:        ParamString,
+			Default:     label,
+			Description: "Label shown before the usage",
+		},
+		rootParam(),
+		{
+			Name:        "models",
+			Type:        ParamInt,
+			Default:     0,
+			Description: "Number of model families listed by cost, with the rest as other, 0 for none",
+		},
+		usageFormatParam(),
+	}
+}
+
+func rootParam() Param {
+	return Param{
+		Name:        "root",
+		Type:        ParamString,
+		Default:     "",
+		Description: "Label of the Claude config dir to count, empty for all",
+	}
+}
+
+func usageOptions(p Params) UsageOptions {
+	return UsageOptions{
+		Label:  p.String("label"),
+		Root:   p.String("root"),
+		Models: p.Int("models"),
+		Format: p.String("format"),
+	}
+}
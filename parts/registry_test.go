@@ -0,0 +1,67 @@
+package parts_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/iskorotkov/cc-statusline/parts"
+)
+
+func TestDefinitions(t *testing.T) {
+	seen := make(map[string]bool)
+	for _, d := range parts.Definitions() {
+		if seen[d.Name] {
+			t.Errorf("duplicate part %q", d.Name)
+		}
+		seen[d.Name] = true
+		if d.Description == "" {
+			t.Errorf("part %q has no description", d.Name)
+		}
+		if _, err := parts.New(d.Name, nil); err != nil {
+			t.Errorf("New(%q) error: %v", d.Name, err)
+		}
+	}
+}
+
+func TestNewParams(t *testing.T) {
+	tests := []struct {
+		name    string
+		params  map[string]any
+		want    string
+		wantErr bool
+	}{
+		{name: "text", params: map[string]any{"text": "hello"}, want: "hello"},
+		{name: "cc.dir", params: map[string]any{"limit": float64(5)}},
+		{name: "cc.dir", params: map[string]any{"limit": int64(5)}},
+		{name: "cc.dir", params: map[string]any{"limit": 5.5}, wantErr: true},
+		{name: "cc.dir", params: map[string]any{"limit": "5"}, wantErr: true},
+		{name: "cc.dir", params: map[string]any{"max": 5}, wantErr: true},
+		{name: "api.burn_rate", params: map[string]any{"window": "1h", "period": "day"}},
+		{name: "api.burn_rate", params: map[string]any{"window": "soon"}, wantErr: true},
+		{name: "api.burn_rate", params: map[string]any{"window": "0s"}, wantErr: true},
+		{name: "api.burn_rate", params: map[string]any{"period": "week"}, wantErr: true},
+		{name: "cc.context", params: map[string]any{"window": int64(500000), "warn": int64(50)}},
+		{name: "api.project", params: map[string]any{"period": "month"}},
+		{name: "api.project", params: map[string]any{"period": "year"}, wantErr: true},
+		{name: "api.cache", params: map[string]any{"period": "day"}},
+		{name: "api.cache", params: map[string]any{"period": "week"}, wantErr: true},
+		{name: "cc.unknown", wantErr: true},
+	}
+	for _, tt := range tests {
+		p, err := parts.New(tt.name, tt.params)
+		if (err != nil) != tt.wantErr {
+			t.Errorf("New(%q, %v) error = %v, wantErr %v", tt.name, tt.params, err, tt.wantErr)
+			continue
+		}
+		if err != nil || tt.want == "" {
+			continue
+		}
+		got, err := p(context.Background(), parts.CCHook{})
+		if err != nil {
+			t.Errorf("part %q error: %v", tt.name, err)
+		}
+		if got != tt.want {
+			t.Errorf("part %q = %q, want %q", tt.name, got, tt.want)
+		}
+	}
+}
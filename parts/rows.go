@@ -2,6 +2,7 @@ package parts
 
 import (
 	"context"
+	"fmt"
 	"strings"
 
 	"github.com/iskorotkov/cc-statusline/style"
@@ -12,43 +13,130 @@ var (
 	partSeparator = style.Dim(" / ")
 )
 
+// Rows evaluates rows concurrently and joins them in order. It waits for
+// every row, relying on Row to give up on parts that miss the deadline.
 func Rows(rows ...Part) Part {
 	return func(ctx context.Context, h CCHook) (string, error) {
 		if len(rows) == 0 {
 			return "", nil
 		}
-		results := make([]string, 0, len(rows))
-		for _, r := range rows {
-			s, err := r(ctx, h)
-			if err != nil {
-				return "", err
-			}
-			if s != "" {
-				results = append(results, s)
-			}
+		outputs, err := evaluate(ctx, h, rows, false)
+		if err != nil {
+			return "", err
 		}
-		return strings.Join(results, rowSeparator), nil
+		return strings.Join(nonEmpty(outputs), rowSeparator), nil
 	}
 }
 
+// Row evaluates parts concurrently and joins them in order. Parts still
+// running when ctx is done render as the configured placeholder, and failed
+// parts render as the error marker unless strict mode is on.
 func Row(prefix string, row ...Part) Part {
 	return func(ctx context.Context, h CCHook) (string, error) {
 		if len(row) == 0 {
 			return "", nil
 		}
-		results := make([]string, 0, len(row))
-		for _, c := range row {
-			s, err := c(ctx, h)
-			if err != nil {
-				return "", err
-			}
-			if s != "" {
-				results = append(results, s)
-			}
+		outputs, err := evaluate(ctx, h, row, true)
+		if err != nil {
+			return "", err
 		}
+		results := nonEmpty(outputs)
 		if len(results) == 0 {
 			return "", nil
 		}
 		return prefix + " " + strings.Join(results, partSeparator), nil
 	}
 }
+
+type result struct {
+	i   int
+	s   string
+	err error
+}
+
+// evaluate runs parts concurrently and returns their outputs in order. With
+// abandon set, parts unfinished when ctx is done are not waited for and are
+// reported to the debug log. A part that panics fails with the panic as its
+// error.
+func evaluate(ctx context.Context, h CCHook, ps []Part, abandon bool) ([]string, error) {
+	results := make(chan result, len(ps))
+	for i, p := range ps {
+		go func() {
+			defer func() {
+				if p := recover(); p != nil {
+					results <- result{i: i, err: fmt.Errorf("panic: %v", p)}
+				}
+			}()
+			s, err := p(ctx, h)
+			results <- result{i: i, s: s, err: err}
+		}()
+	}
+	outputs := make([]string, len(ps))
+	errs := make([]error, len(ps))
+	finished := make([]bool, len(ps))
+	record := func(r result) {
+		outputs[r.i], finished[r.i] = r.s, true
+		if r.err != nil {
+			if settings.Strict {
+				logFailure(r.err)
+				errs[r.i] = r.err
+			} else {
+				outputs[r.i] = failed(r.err)
+			}
+		}
+	}
+	for range ps {
+		if !abandon {
+			record(<-results)
+			continue
+		}
+		select {
+		case r := <-results:
+			record(r)
+		case <-ctx.Done():
+			// Parts may have finished at the same time as the deadline.
+			for drained := false; !drained; {
+				select {
+				case r := <-results:
+					record(r)
+				default:
+					drained = true
+				}
+			}
+			logAbandoned()
+			for i := range ps {
+				if !finished[i] {
+					outputs[i] = placeholder()
+				}
+			}
+			return outputs, firstError(errs)
+		}
+	}
+	return outputs, firstError(errs)
+}
+
+func placeholder() string {
+	if settings.Placeholder == "" {
+		return ""
+	}
+	return style.Dim(settings.Placeholder)
+}
+
+func firstError(errs []error) error {
+	for _, err := range errs {
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func nonEmpty(s []string) []string {
+	results := make([]string, 0, len(s))
+	for _, s := range s {
+		if s != "" {
+			results = append(results, s)
+		}
+	}
+	return results
+}
@@ -0,0 +1,134 @@
+package parts_test
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"log/slog"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/iskorotkov/cc-statusline/parts"
+)
+
+func sleepy(s string, d time.Duration) parts.Part {
+	return func(ctx context.Context, h parts.CCHook) (string, error) {
+		time.Sleep(d)
+		return s, nil
+	}
+}
+
+func TestRowOrder(t *testing.T) {
+	r := parts.Rows(
+		parts.Row("A", sleepy("1", 30*time.Millisecond), sleepy("2", 10*time.Millisecond), sleepy("", 0)),
+		parts.Row("B", sleepy("3", 0)),
+		parts.Row("C"),
+	)
+	got, err := r(context.Background(), parts.CCHook{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := "A 1" + "\x1b[0m\x1b[2m / \x1b[0m" + "2\nB 3"
+	if got != want {
+		t.Errorf("Rows() = %q, want %q", got, want)
+	}
+}
+
+func TestRowConcurrent(t *testing.T) {
+	r := parts.Row("A", sleepy("1", 50*time.Millisecond), sleepy("2", 50*time.Millisecond), sleepy("3", 50*time.Millisecond))
+	start := time.Now()
+	if _, err := r(context.Background(), parts.CCHook{}); err != nil {
+		t.Fatal(err)
+	}
+	if elapsed := time.Since(start); elapsed > 120*time.Millisecond {
+		t.Errorf("parts were not evaluated concurrently, took %s", elapsed)
+	}
+}
+
+func configure(t *testing.T, s parts.Settings) {
+	t.Helper()
+	if err := parts.Configure(s); err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() {
+		_ = parts.Configure(parts.Settings{})
+	})
+}
+
+func TestRowDeadline(t *testing.T) {
+	configure(t, parts.Settings{Placeholder: "..."})
+
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+	r := parts.Rows(parts.Row("A", sleepy("fast", 0), sleepy("slow", time.Second)))
+	start := time.Now()
+	got, err := r(ctx, parts.CCHook{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if elapsed := time.Since(start); elapsed > 500*time.Millisecond {
+		t.Errorf("deadline was not respected, took %s", elapsed)
+	}
+	want := "A fast" + "\x1b[0m\x1b[2m / \x1b[0m" + "\x1b[0m\x1b[2m...\x1b[0m"
+	if got != want {
+		t.Errorf("Rows() = %q, want %q", got, want)
+	}
+}
+
+func failing(err error) parts.Part {
+	return func(ctx context.Context, h parts.CCHook) (string, error) {
+		return "", err
+	}
+}
+
+func TestRowErrorMarker(t *testing.T) {
+	configure(t, parts.Settings{ErrorMarker: `!{{.Group}}`})
+
+	failingBranch, err := parts.New("text", map[string]any{"text": "{{.Missing}}"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	r := parts.Rows(parts.Row("A", sleepy("1", 0), failingBranch, failing(errors.New("unnamed"))))
+	got, err := r(context.Background(), parts.CCHook{})
+	if err != nil {
+		t.Fatalf("Rows() error: %v", err)
+	}
+	want := "A 1" + "\x1b[0m\x1b[2m / \x1b[0m" + "!text" + "\x1b[0m\x1b[2m / \x1b[0m" + "!"
+	if got != want {
+		t.Errorf("Rows() = %q, want %q", got, want)
+	}
+}
+
+func TestRowStrict(t *testing.T) {
+	configure(t, parts.Settings{Strict: true})
+	var log bytes.Buffer
+	defer slog.SetDefault(slog.Default())
+	slog.SetDefault(slog.New(slog.NewTextHandler(&log, nil)))
+
+	want := errors.New("first")
+	r := parts.Row("A", sleepy("1", 0), failing(want), failing(errors.New("second")))
+	if _, err := r(context.Background(), parts.CCHook{}); !errors.Is(err, want) {
+		t.Errorf("Row() error = %v, want %v", err, want)
+	}
+	if !strings.Contains(log.String(), "err=first") || !strings.Contains(log.String(), "err=second") {
+		t.Errorf("debug log = %q, want both errors", log.String())
+	}
+}
+
+func TestRowPanic(t *testing.T) {
+	configure(t, parts.Settings{ErrorMarker: `!{{.Error}}`})
+
+	panicking := func(ctx context.Context, h parts.CCHook) (string, error) {
+		panic("boom")
+	}
+	r := parts.Rows(parts.Row("A", sleepy("1", 0), panicking))
+	got, err := r(context.Background(), parts.CCHook{})
+	if err != nil {
+		t.Fatalf("Rows() error: %v", err)
+	}
+	want := "A 1" + "\x1b[0m\x1b[2m / \x1b[0m" + "!panic: boom"
+	if got != want {
+		t.Errorf("Rows() = %q, want %q", got, want)
+	}
+}
@@ -0,0 +1,73 @@
+package parts
+
+import (
+	"fmt"
+	"slices"
+	"text/template"
+	"time"
+
+	"github.com/iskorotkov/cc-statusline/pricing"
+	"github.com/iskorotkov/cc-statusline/transcript"
+)
+
+// Settings holds options shared by all parts.
+type Settings struct {
+	// Placeholder is shown for parts that miss the render deadline.
+	Placeholder string
+	// Strict makes Row and Rows fail when any part fails.
+	Strict bool
+	// ErrorMarker is a template over ErrorData shown in place of a failed
+	// part. Failed parts are hidden when it is empty.
+	ErrorMarker string
+	// CacheTTL is the time to live of values cached on disk, by source name.
+	// Sources without a TTL are not cached.
+	CacheTTL map[string]time.Duration
+	// Roots are Claude config dirs read in addition to the default one.
+	Roots []transcript.Root
+	// Location is the timezone of calendar usage windows, local if nil.
+	Location *time.Location
+	// WeekStart is the first day of calendar weeks.
+	WeekStart time.Weekday
+	// Budget limits the cost of usage windows.
+	Budget Budget
+	// Pricing adds or replaces model prices, by model ID or prefix.
+	Pricing map[string]pricing.Pricing
+}
+
+var (
+	settings    Settings
+	errorMarker *template.Template
+)
+
+// Configure sets options shared by all parts. It must be called before
+// any part is evaluated.
+func Configure(s Settings) error {
+	for name := range s.CacheTTL {
+		if _, ok := sources[name]; !ok {
+			return fmt.Errorf("unknown cache source %q", name)
+		}
+	}
+	for name := range s.Budget.Limits {
+		if !slices.Contains(budgetWindows, name) {
+			return fmt.Errorf("unknown budget window %q, expected one of %v", name, budgetWindows)
+		}
+	}
+	t, err := template.New("error_marker").Funcs(formatFuncs).Parse(s.ErrorMarker)
+	if err != nil {
+		return fmt.Errorf("parse error marker: %w", err)
+	}
+	pricing.Configure(s.Pricing)
+	settings, errorMarker = s, t
+	return nil
+}
+
+// clock returns the current time.
+var clock = time.Now
+
+// now returns the current time in the configured timezone.
+func now() time.Time {
+	if settings.Location == nil {
+		return clock()
+	}
+	return clock().In(settings.Location)
+}
@@ -0,0 +1,32 @@
+package parts_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/iskorotkov/cc-statusline/parts"
+)
+
+func TestConfigure(t *testing.T) {
+	tests := []struct {
+		name     string
+		settings parts.Settings
+		wantErr  bool
+	}{
+		{name: "empty", settings: parts.Settings{}},
+		{name: "budget", settings: parts.Settings{Budget: parts.Budget{Limits: map[string]float64{"day": 20, "month": 300}}}},
+		{name: "unknown budget window", settings: parts.Settings{Budget: parts.Budget{Limits: map[string]float64{"year": 1}}}, wantErr: true},
+		{name: "unknown cache source", settings: parts.Settings{CacheTTL: map[string]time.Duration{"gh_issue": time.Minute}}, wantErr: true},
+		{name: "invalid error marker", settings: parts.Settings{ErrorMarker: "{{.Name"}, wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Cleanup(func() {
+				_ = parts.Configure(parts.Settings{})
+			})
+			if err := parts.Configure(tt.settings); (err != nil) != tt.wantErr {
+				t.Errorf("Configure() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
@@ -0,0 +1,46 @@
+package parts
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"sync"
+
+	"github.com/iskorotkov/cc-statusline/cache"
+)
+
+type refresher interface {
+	Refresh(ctx context.Context) error
+}
+
+// sources holds values cached on disk between runs, by name.
+var sources = make(map[string]refresher)
+
+// cached registers s and returns a function that reads it at most once per
+// process, with the TTL configured for s.
+func cached[T any](s *cache.Source[T]) func(ctx context.Context) (T, error) {
+	sources[s.Name] = s
+	var v T
+	var err error
+	var once sync.Once
+	return func(ctx context.Context) (T, error) {
+		once.Do(func() {
+			v, err = s.Get(ctx, settings.CacheTTL[s.Name])
+		})
+		return v, err
+	}
+}
+
+// RefreshSource fetches the named source and updates its cache entry. It is
+// run in a background process when a cached value goes stale.
+func RefreshSource(ctx context.Context, name string) error {
+	s, ok := sources[name]
+	if !ok {
+		return fmt.Errorf("unknown source %q", name)
+	}
+	return s.Refresh(ctx)
+}
+
+func dirKey(ctx context.Context) (string, error) {
+	return os.Getwd()
+}
@@ -37,8 +37,11 @@ func count(s string) []pair {
 }
 
 func limit(s string, n int) string {
-	if len(s) <= n {
+	if n <= 0 || len(s) <= n {
 		return s
 	}
+	if n <= 3 {
+		return s[:n]
+	}
 	return strings.TrimSpace(s[:n-3]) + "..."
 }
@@ -4,26 +4,27 @@ import (
 	"context"
 	"os"
 	"regexp"
-
-	"github.com/iskorotkov/cc-statusline/style"
 )
 
 var taskRegex = regexp.MustCompile(`(\w+\-?|#)?\d+`)
 
-func TaskURL() Part {
-	return func(ctx context.Context, h CCHook) (string, error) {
+func TaskURL(server, format string) (Part, error) {
+	return Formatted(format, func(ctx context.Context, h CCHook) (TaskData, bool, error) {
 		branch, _ := gitBranchShowCurrent(ctx)
 		pr, _ := ghPRViewJSON(ctx)
-		taskServer := os.Getenv("CC_TASK_SERVER")
+		taskServer := server
+		if taskServer == "" {
+			taskServer = os.Getenv("CC_TASK_SERVER")
+		}
 		if taskServer == "" {
-			return "", nil
+			return TaskData{}, false, nil
 		}
 		taskCode, ok := extractTaskCode(branch, pr.HeadRefName)
 		if !ok {
-			return "", nil
+			return TaskData{}, false, nil
 		}
-		return style.Underline(taskServer + "/" + taskCode), nil
-	}
+		return TaskData{Code: taskCode, URL: taskServer + "/" + taskCode}, true, nil
+	})
 }
 
 func extractTaskCode(s ...string) (string, bool) {
@@ -0,0 +1,72 @@
+package pricing
+
+import (
+	"maps"
+	"regexp"
+	"slices"
+	"strings"
+)
+
+// Providers serving Claude models other than the Anthropic API.
+const (
+	ProviderBedrock = "bedrock"
+	ProviderVertex  = "vertex"
+)
+
+// Model is a model ID without provider specific parts.
+type Model struct {
+	// ID is the model ID as used by the Anthropic API, such as
+	// claude-sonnet-4-20250514.
+	ID string
+	// Provider is "bedrock", "vertex", or empty for the Anthropic API.
+	Provider string
+}
+
+var (
+	bedrockRegion  = regexp.MustCompile(`^[a-z]{2,6}(-[a-z]+)?\.anthropic\.`)
+	bedrockVersion = regexp.MustCompile(`-v\d+:\d+$`)
+)
+
+// Normalize converts model IDs of Bedrock, such as
+// us.anthropic.claude-sonnet-4-20250514-v1:0 or an inference profile ARN,
+// and of Vertex, such as claude-sonnet-4@20250514, to the IDs of the
+// Anthropic API, so that usage of the same model is priced and grouped
+// together.
+func Normalize(model string) Model {
+	id := strings.ToLower(model)
+	provider := ""
+	if strings.HasPrefix(id, "arn:") {
+		id = id[strings.LastIndex(id, "/")+1:]
+		provider = ProviderBedrock
+	}
+	if loc := bedrockRegion.FindStringIndex(id); loc != nil {
+		id = id[loc[1]:]
+		provider = ProviderBedrock
+	} else if rest, ok := strings.CutPrefix(id, "anthropic."); ok {
+		id = rest
+		provider = ProviderBedrock
+	}
+	if provider == ProviderBedrock {
+		id = bedrockVersion.ReplaceAllString(id, "")
+	}
+	if name, version, ok := strings.Cut(id, "@"); ok {
+		id = name
+		if version != "latest" {
+			id += "-" + version
+		}
+		provider = ProviderVertex
+	}
+	return Model{ID: id, Provider: provider}
+}
+
+// ShortName returns the family of the model, such as "opus", or its
+// normalized ID if the family is unknown.
+func ShortName(model string) string {
+	id := Normalize(model).ID
+	for _, family := range slices.Sorted(maps.Keys(families)) {
+		if strings.Contains(id, family) {
+			return family
+		}
+	}
+	return id
+}
@@ -0,0 +1,47 @@
+package pricing_test
+
+import (
+	"testing"
+
+	"github.com/iskorotkov/cc-statusline/pricing"
+)
+
+func TestNormalize(t *testing.T) {
+	tests := []struct {
+		model string
+		want  pricing.Model
+	}{
+		{model: "claude-sonnet-4-20250514", want: pricing.Model{ID: "claude-sonnet-4-20250514"}},
+		{model: "anthropic.claude-3-5-haiku-20241022-v1:0", want: pricing.Model{ID: "claude-3-5-haiku-20241022", Provider: pricing.ProviderBedrock}},
+		{model: "us.anthropic.claude-sonnet-4-20250514-v1:0", want: pricing.Model{ID: "claude-sonnet-4-20250514", Provider: pricing.ProviderBedrock}},
+		{model: "global.anthropic.claude-sonnet-4-5-20250929-v1:0", want: pricing.Model{ID: "claude-sonnet-4-5-20250929", Provider: pricing.ProviderBedrock}},
+		{
+			model: "arn:aws:bedrock:us-east-1:123456789012:inference-profile/eu.anthropic.claude-opus-4-1-20250805-v1:0",
+			want:  pricing.Model{ID: "claude-opus-4-1-20250805", Provider: pricing.ProviderBedrock},
+		},
+		{model: "claude-sonnet-4@20250514", want: pricing.Model{ID: "claude-sonnet-4-20250514", Provider: pricing.ProviderVertex}},
+		{model: "claude-3-5-sonnet-v2@20241022", want: pricing.Model{ID: "claude-3-5-sonnet-v2-20241022", Provider: pricing.ProviderVertex}},
+		{model: "claude-opus-4-1@latest", want: pricing.Model{ID: "claude-opus-4-1", Provider: pricing.ProviderVertex}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.model, func(t *testing.T) {
+			if got := pricing.Normalize(tt.model); got != tt.want {
+				t.Errorf("Normalize(%q) = %+v, want %+v", tt.model, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestShortName(t *testing.T) {
+	tests := map[string]string{
+		"claude-opus-4-1-20250805":                   "opus",
+		"us.anthropic.claude-sonnet-4-20250514-v1:0": "sonnet",
+		"claude-3-5-haiku@20241022":                  "haiku",
+		"gpt-5":                                      "gpt-5",
+	}
+	for model, want := range tests {
+		if got := pricing.ShortName(model); got != want {
+			t.Errorf("ShortName(%q) = %q, want %q", model, got, want)
+		}
+	}
+}
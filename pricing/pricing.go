@@ -1,28 +1,229 @@
 package pricing
 
-var pricingByModel = map[string]Pricing{
-	"claude-opus-4-1-20250805": {
-		InputTokens:      15e-6,
-		OutputTokens:     75e-6,
-		CacheWriteTokens: 18.75e-6,
-		CacheReadTokens:  1.5e-6,
-	},
-	"claude-sonnet-4-20250514": {
-		InputTokens:      3e-6,
-		OutputTokens:     15e-6,
-		CacheWriteTokens: 3.75e-6,
-		CacheReadTokens:  0.3e-6,
-	},
-}
+import (
+	"cmp"
+	_ "embed"
+	"encoding/json"
+	"fmt"
+	"maps"
+	"regexp"
+	"slices"
+	"strings"
+
+	"github.com/iskorotkov/cc-statusline/transcript"
+)
 
+// modelsJSON holds prices in dollars per million tokens by model ID prefix,
+// prices of server tools in dollars per request, context window sizes in
+// tokens by model ID prefix, and the model used for each family when no
+// prefix matches.
+//
+//go:embed models.json
+var modelsJSON []byte
+
+// Pricing holds prices of a model in dollars per token.
 type Pricing struct {
-	InputTokens      float64
-	OutputTokens     float64
-	CacheWriteTokens float64
-	CacheReadTokens  float64
+	InputTokens  float64
+	OutputTokens float64
+	// CacheWriteTokens is the price of writes to the 5-minute cache, and
+	// CacheWrite1hTokens of writes to the 1-hour cache.
+	CacheWriteTokens   float64
+	CacheWrite1hTokens float64
+	CacheReadTokens    float64
+	// LongContextInput and LongContextOutput multiply input and output
+	// prices of requests over transcript.LongContextTokens of input. Zero
+	// means the model has no long context rates.
+	LongContextInput  float64
+	LongContextOutput float64
+	// WebSearchRequests and WebFetchRequests are prices per server tool
+	// request.
+	WebSearchRequests float64
+	WebFetchRequests  float64
+}
+
+// perMillion is a price as written in models.json.
+type perMillion struct {
+	Input             float64 `json:"input"`
+	Output            float64 `json:"output"`
+	CacheWrite        float64 `json:"cache_write"`
+	CacheWrite1h      float64 `json:"cache_write_1h"`
+	CacheRead         float64 `json:"cache_read"`
+	LongContextInput  float64 `json:"long_context_input"`
+	LongContextOutput float64 `json:"long_context_output"`
+}
+
+var (
+	defaultModels, serverTools, contextWindows, families = load()
+	pricingByModel                                       = defaultModels
+)
+
+var dateSuffix = regexp.MustCompile(`-\d{8}$`)
+
+// toolPrices are prices of server tools per request.
+type toolPrices struct {
+	WebSearch float64 `json:"web_search"`
+	WebFetch  float64 `json:"web_fetch"`
+}
+
+func load() (map[string]Pricing, toolPrices, map[string]int, map[string]string) {
+	var data struct {
+		Models         map[string]perMillion `json:"models"`
+		ServerTools    toolPrices            `json:"server_tools"`
+		ContextWindows map[string]int        `json:"context_windows"`
+		Families       map[string]string     `json:"families"`
+	}
+	if err := json.Unmarshal(modelsJSON, &data); err != nil {
+		panic(fmt.Sprintf("decode model pricing: %v", err))
+	}
+	models := make(map[string]Pricing, len(data.Models))
+	for model, p := range data.Models {
+		models[model] = Pricing{
+			InputTokens:        p.Input / 1e6,
+			OutputTokens:       p.Output / 1e6,
+			CacheWriteTokens:   p.CacheWrite / 1e6,
+			CacheWrite1hTokens: p.CacheWrite1h / 1e6,
+			CacheReadTokens:    p.CacheRead / 1e6,
+			LongContextInput:   p.LongContextInput,
+			LongContextOutput:  p.LongContextOutput,
+			WebSearchRequests:  data.ServerTools.WebSearch,
+			WebFetchRequests:   data.ServerTools.WebFetch,
+		}
+	}
+	return models, data.ServerTools, data.ContextWindows, data.Families
 }
 
+// Configure adds prices to the built-in ones, replacing prices of the same
+// models. Server tool prices left at zero default to the built-in ones. It
+// must be called before any cost is computed.
+func Configure(prices map[string]Pricing) {
+	models := maps.Clone(defaultModels)
+	for model, p := range prices {
+		p.WebSearchRequests = cmp.Or(p.WebSearchRequests, serverTools.WebSearch)
+		p.WebFetchRequests = cmp.Or(p.WebFetchRequests, serverTools.WebFetch)
+		models[strings.ToLower(model)] = p
+	}
+	pricingByModel = models
+}
+
+// ModelPricing returns the price of the model per token. Bedrock and Vertex
+// model IDs are normalized first, and prices set for their provider, such
+// as "bedrock/claude-sonnet-4", take precedence. Models are matched exactly,
+// then without the date suffix, then by the longest known prefix, so new
+// dated variants of known models are priced too. It reports false if
+// nothing matches.
 func ModelPricing(model string) (Pricing, bool) {
-	p, ok := pricingByModel[model]
-	return p, ok
+	m := Normalize(model)
+	if m.Provider != "" {
+		if p, ok := match(pricingByModel, m.Provider+"/"+m.ID); ok {
+			return p, true
+		}
+	}
+	return match(pricingByModel, m.ID)
+}
+
+// familyPricing returns the price of the latest model of the family of the
+// model, such as opus, to estimate costs of models without known prices.
+func familyPricing(model string) (Pricing, bool) {
+	id := Normalize(model).ID
+	for _, family := range slices.Sorted(maps.Keys(families)) {
+		if strings.Contains(id, family) {
+			p, ok := pricingByModel[families[family]]
+			return p, ok
+		}
+	}
+	return Pricing{}, false
+}
+
+// ContextWindow returns the size of the standard context window of the
+// model in tokens. Models are matched like in ModelPricing, without the
+// provider specific prices. It reports false if nothing matches.
+func ContextWindow(model string) (int, bool) {
+	id := Normalize(model).ID
+	if n, ok := match(contextWindows, id); ok {
+		return n, true
+	}
+	for _, family := range slices.Sorted(maps.Keys(families)) {
+		if strings.Contains(id, family) {
+			return match(contextWindows, families[family])
+		}
+	}
+	return 0, false
+}
+
+// match looks up the value of id in models exactly, without the date
+// suffix, or by the longest known prefix.
+func match[T any](models map[string]T, id string) (T, bool) {
+	if v, ok := models[id]; ok {
+		return v, true
+	}
+	id = dateSuffix.ReplaceAllString(id, "")
+	if v, ok := models[id]; ok {
+		return v, true
+	}
+	prefix := ""
+	for known := range models {
+		if strings.HasPrefix(id, known+"-") && len(known) > len(prefix) {
+			prefix = known
+		}
+	}
+	if prefix == "" {
+		var zero T
+		return zero, false
+	}
+	return models[prefix], true
+}
+
+// Cost returns the cost of usage of the model in dollars, including server
+// tool requests. Usage by long context requests is billed at long context
+// rates, and 1-hour cache writes at their own rate. It reports false if the
+// model has no known pricing, in which case the cost is estimated from the
+// latest model of its family, or zero if the family is unknown too.
+func Cost(model string, usage transcript.Usage) (float64, bool) {
+	p, ok := ModelPricing(model)
+	if !ok {
+		p, _ = familyPricing(model)
+	}
+	long := usage.LongContext
+	standard := transcript.Tokens{
+		InputTokens:        usage.InputTokens - long.InputTokens,
+		OutputTokens:       usage.OutputTokens - long.OutputTokens,
+		CacheWriteTokens:   usage.CacheWriteTokens - long.CacheWriteTokens,
+		CacheWrite1hTokens: usage.CacheWrite1hTokens - long.CacheWrite1hTokens,
+		CacheReadTokens:    usage.CacheReadTokens - long.CacheReadTokens,
+	}
+	return p.cost(standard, 1, 1) +
+		p.cost(long, cmp.Or(p.LongContextInput, 1), cmp.Or(p.LongContextOutput, 1)) +
+		p.toolCost(usage), ok
+}
+
+// CacheSavings returns how much cheaper usage of the model was than if all
+// cache reads and writes were billed as uncached input. It is negative when
+// cache writes cost more than reads saved. It reports false if the model has
+// no known pricing, in which case savings are estimated like in Cost.
+func CacheSavings(model string, usage transcript.Usage) (float64, bool) {
+	cost, ok := Cost(model, usage)
+	uncached := usage
+	uncached.InputTokens += usage.CacheWriteTokens + usage.CacheReadTokens
+	uncached.CacheWriteTokens, uncached.CacheWrite1hTokens, uncached.CacheReadTokens = 0, 0, 0
+	long := &uncached.LongContext
+	long.InputTokens += long.CacheWriteTokens + long.CacheReadTokens
+	long.CacheWriteTokens, long.CacheWrite1hTokens, long.CacheReadTokens = 0, 0, 0
+	full, _ := Cost(model, uncached)
+	return full - cost, ok
+}
+
+// toolCost returns the cost of server tool requests of usage.
+func (p Pricing) toolCost(usage transcript.Usage) float64 {
+	return float64(usage.WebSearchRequests)*p.WebSearchRequests +
+		float64(usage.WebFetchRequests)*p.WebFetchRequests
+}
+
+// cost returns the cost of tokens with input and output prices multiplied
+// by in and out.
+func (p Pricing) cost(t transcript.Tokens, in, out float64) float64 {
+	return in*(float64(t.InputTokens)*p.InputTokens+
+		float64(t.CacheWriteTokens-t.CacheWrite1hTokens)*p.CacheWriteTokens+
+		float64(t.CacheWrite1hTokens)*p.CacheWrite1hTokens+
+		float64(t.CacheReadTokens)*p.CacheReadTokens) +
+		out*float64(t.OutputTokens)*p.OutputTokens
 }
@@ -1,8 +1,10 @@
 package pricing_test
 
 import (
+	"math"
 	"os"
 	"testing"
+	"time"
 
 	"github.com/iskorotkov/cc-statusline/pricing"
 	"github.com/iskorotkov/cc-statusline/transcript"
@@ -16,7 +18,7 @@ func TestModelPricing(t *testing.T) {
 	if err != nil {
 		t.Fatalf("Failed to parse transcripts: %v", err)
 	}
-	usages := transcript.UsageByDate(transcripts)
+	usages := transcript.UsageByDate(transcripts, time.Local)
 	for k := range usages {
 		p, ok := pricing.ModelPricing(k.Model)
 		if !ok {
@@ -25,3 +27,186 @@ func TestModelPricing(t *testing.T) {
 		_ = p
 	}
 }
+
+func TestModelPricingMatch(t *testing.T) {
+	t.Cleanup(func() {
+		pricing.Configure(nil)
+	})
+	pricing.Configure(map[string]pricing.Pricing{
+		"claude-custom": {InputTokens: 1e-6},
+	})
+	tests := []struct {
+		model string
+		input float64
+		ok    bool
+	}{
+		{model: "claude-opus-4-1-20250805", input: 15e-6, ok: true},
+		{model: "claude-sonnet-4-20250514", input: 3e-6, ok: true},
+		{model: "claude-sonnet-4-5", input: 3e-6, ok: true},
+		{model: "claude-haiku-4-5-20251001", input: 1e-6, ok: true},
+		{model: "claude-3-5-haiku-20241022", input: 0.8e-6, ok: true},
+		{model: "claude-opus-4-1-20250805-preview", input: 15e-6, ok: true},
+		{model: "claude-opus-5-5", ok: false},
+		{model: "Sonnet", ok: false},
+		{model: "claude-custom-20260101", input: 1e-6, ok: true},
+		{model: "us.anthropic.claude-opus-4-1-20250805-v1:0", input: 15e-6, ok: true},
+		{model: "claude-3-5-sonnet-v2@20241022", input: 3e-6, ok: true},
+		{model: "<synthetic>", ok: false},
+		{model: "gpt-5", ok: false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.model, func(t *testing.T) {
+			p, ok := pricing.ModelPricing(tt.model)
+			if ok != tt.ok || math.Abs(p.InputTokens-tt.input) > 1e-12 {
+				t.Errorf("ModelPricing(%q) = %v, %v, want input %v, %v", tt.model, p.InputTokens, ok, tt.input, tt.ok)
+			}
+		})
+	}
+}
+
+func TestContextWindow(t *testing.T) {
+	tests := []struct {
+		model  string
+		window int
+		ok     bool
+	}{
+		{model: "claude-sonnet-4-5-20250929", window: 200_000, ok: true},
+		{model: "claude-3-5-haiku-20241022", window: 200_000, ok: true},
+		{model: "claude-2.0", window: 100_000, ok: true},
+		{model: "us.anthropic.claude-opus-4-1-20250805-v1:0", window: 200_000, ok: true},
+		{model: "claude-opus-5-5", window: 200_000, ok: true},
+		{model: "gpt-5", ok: false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.model, func(t *testing.T) {
+			if window, ok := pricing.ContextWindow(tt.model); window != tt.window || ok != tt.ok {
+				t.Errorf("ContextWindow(%q) = %d, %v, want %d, %v", tt.model, window, ok, tt.window, tt.ok)
+			}
+		})
+	}
+}
+
+func TestConfigure(t *testing.T) {
+	t.Cleanup(func() {
+		pricing.Configure(nil)
+	})
+	usage := transcript.Usage{InputTokens: 1_000_000}
+	pricing.Configure(map[string]pricing.Pricing{
+		"claude-sonnet-4": {InputTokens: 2e-6},
+	})
+	if cost, ok := pricing.Cost("claude-sonnet-4-20250514", usage); !ok || cost != 2 {
+		t.Errorf("Cost() with override = %v, %v, want 2, true", cost, ok)
+	}
+	searches := transcript.Usage{WebSearchRequests: 100}
+	if cost, ok := pricing.Cost("claude-sonnet-4-20250514", searches); !ok || math.Abs(cost-1) > 1e-9 {
+		t.Errorf("Cost() of web searches with override = %v, %v, want built-in 1, true", cost, ok)
+	}
+
+	pricing.Configure(map[string]pricing.Pricing{
+		"bedrock/claude-sonnet-4": {InputTokens: 3.3e-6},
+	})
+	if cost, ok := pricing.Cost("us.anthropic.claude-sonnet-4-20250514-v1:0", usage); !ok || math.Abs(cost-3.3) > 1e-9 {
+		t.Errorf("Cost() on Bedrock with override = %v, %v, want 3.3, true", cost, ok)
+	}
+	if cost, ok := pricing.Cost("claude-sonnet-4@20250514", usage); !ok || cost != 3 {
+		t.Errorf("Cost() on Vertex with Bedrock override = %v, %v, want 3, true", cost, ok)
+	}
+
+	pricing.Configure(nil)
+	if cost, ok := pricing.Cost("claude-sonnet-4-20250514", usage); !ok || cost != 3 {
+		t.Errorf("Cost() after reset = %v, %v, want 3, true", cost, ok)
+	}
+}
+
+func TestCost(t *testing.T) {
+	tests := []struct {
+		name  string
+		model string
+		usage transcript.Usage
+		want  float64
+		// unknown is set for models without known pricing.
+		unknown bool
+	}{
+		{
+			name:  "5m and 1h cache writes",
+			model: "claude-sonnet-4-20250514",
+			usage: transcript.Usage{CacheWriteTokens: 3_000_000, CacheWrite1hTokens: 1_000_000},
+			want:  2*3.75 + 6,
+		},
+		{
+			name:  "long context",
+			model: "claude-sonnet-4-20250514",
+			usage: transcript.Usage{
+				InputTokens:     2_000_000,
+				OutputTokens:    2_000_000,
+				CacheReadTokens: 1_000_000,
+				LongContext:     transcript.Tokens{InputTokens: 1_000_000, OutputTokens: 1_000_000, CacheReadTokens: 1_000_000},
+			},
+			want: 3 + 15 + 6 + 22.5 + 0.6,
+		},
+		{
+			name:  "server tools",
+			model: "claude-sonnet-4-20250514",
+			usage: transcript.Usage{OutputTokens: 1_000_000, WebSearchRequests: 100, WebFetchRequests: 10},
+			want:  15 + 1,
+		},
+		{
+			name:  "long context without long context rates",
+			model: "claude-opus-4-1-20250805",
+			usage: transcript.Usage{InputTokens: 1_000_000, LongContext: transcript.Tokens{InputTokens: 1_000_000}},
+			want:  15,
+		},
+		{
+			name:    "estimated from family",
+			model:   "claude-opus-5-5",
+			usage:   transcript.Usage{InputTokens: 1_000_000},
+			want:    5,
+			unknown: true,
+		},
+		{
+			name:    "unknown family",
+			model:   "gpt-5",
+			usage:   transcript.Usage{InputTokens: 1_000_000},
+			want:    0,
+			unknown: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cost, ok := pricing.Cost(tt.model, tt.usage)
+			if ok == tt.unknown || math.Abs(cost-tt.want) > 1e-9 {
+				t.Errorf("Cost() = %v, %v, want %v, %v", cost, ok, tt.want, !tt.unknown)
+			}
+		})
+	}
+}
+
+func TestCacheSavings(t *testing.T) {
+	tests := []struct {
+		name  string
+		usage transcript.Usage
+		want  float64
+	}{
+		{name: "reads", usage: transcript.Usage{CacheReadTokens: 1_000_000}, want: 3 - 0.3},
+		{name: "writes only", usage: transcript.Usage{CacheWriteTokens: 1_000_000}, want: 3 - 3.75},
+		{
+			name: "long context reads",
+			usage: transcript.Usage{
+				CacheReadTokens: 1_000_000,
+				LongContext:     transcript.Tokens{CacheReadTokens: 1_000_000},
+			},
+			want: 2 * (3 - 0.3),
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, ok := pricing.CacheSavings("claude-sonnet-4-20250514", tt.usage)
+			if !ok || math.Abs(got-tt.want) > 1e-9 {
+				t.Errorf("CacheSavings() = %v, %v, want %v, true", got, ok, tt.want)
+			}
+		})
+	}
+	if _, ok := pricing.CacheSavings("gpt-5", transcript.Usage{CacheReadTokens: 1}); ok {
+		t.Error("CacheSavings() of an unknown model reported pricing")
+	}
+}
@@ -0,0 +1,331 @@
+// Package report aggregates transcript usage into tables grouped by time
+// period, session, model or project.
+package report
+
+import (
+	"cmp"
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"maps"
+	"slices"
+	"strconv"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"github.com/iskorotkov/cc-statusline/pricing"
+	"github.com/iskorotkov/cc-statusline/transcript"
+)
+
+// Groupings of report rows.
+const (
+	ByDay     = "day"
+	ByWeek    = "week"
+	ByMonth   = "month"
+	BySession = "session"
+	ByModel   = "model"
+	ByProject = "project"
+)
+
+// Groupings lists all groupings in the order shown in help.
+var Groupings = []string{ByDay, ByWeek, ByMonth, BySession, ByModel, ByProject}
+
+// Output formats.
+const (
+	FormatText     = "text"
+	FormatJSON     = "json"
+	FormatCSV      = "csv"
+	FormatMarkdown = "markdown"
+)
+
+// Formats lists all output formats.
+var Formats = []string{FormatText, FormatJSON, FormatCSV, FormatMarkdown}
+
+type Options struct {
+	// By is the grouping of rows.
+	By string
+	// Since and Until limit events to [Since, Until). Zero values leave the
+	// range open.
+	Since time.Time
+	Until time.Time
+	// Location is the timezone of days, weeks and months.
+	Location *time.Location
+	// WeekStart is the first day of weeks.
+	WeekStart time.Weekday
+	// Branch limits events to the git branch, if set.
+	Branch string
+	// Top limits rows grouped by session, model or project to the most
+	// expensive ones, with the rest summed in an "other" row. 0 shows all.
+	Top int
+}
+
+// Row is usage of a group of events.
+type Row struct {
+	Group            string   `json:"group"`
+	Models           []string `json:"models"`
+	InputTokens      int      `json:"input_tokens"`
+	OutputTokens     int      `json:"output_tokens"`
+	CacheWriteTokens int      `json:"cache_write_tokens"`
+	CacheReadTokens  int      `json:"cache_read_tokens"`
+	TotalTokens      int      `json:"total_tokens"`
+	// WebSearchRequests and WebFetchRequests count server tool requests,
+	// which are billed per request and included in the cost.
+	WebSearchRequests int     `json:"web_search_requests"`
+	WebFetchRequests  int     `json:"web_fetch_requests"`
+	Cost              float64 `json:"cost"`
+	// Unpriced lists models with usage but no known pricing, whose cost is
+	// estimated from their family or left out.
+	Unpriced []string `json:"unpriced,omitempty"`
+}
+
+// Build groups usage of transcripts into rows. Rows of time periods are
+// sorted by time, other rows by cost with the most expensive first.
+func Build(transcripts []transcript.Transcript, opts Options) ([]Row, error) {
+	key, err := groupKey(opts)
+	if err != nil {
+		return nil, err
+	}
+	rows := make(map[string]*Row)
+	for k, usage := range transcript.UsageByGroup(transcripts, func(t transcript.Transcript, e transcript.Event) string {
+		if !opts.Since.IsZero() && e.Timestamp.Before(opts.Since) {
+			return ""
+		}
+		if !opts.Until.IsZero() && !e.Timestamp.Before(opts.Until) {
+			return ""
+		}
+		if opts.Branch != "" && e.GitBranch != opts.Branch {
+			return ""
+		}
+		return key(t, e)
+	}) {
+		r := rows[k.Group]
+		if r == nil {
+			r = &Row{Group: k.Group}
+			rows[k.Group] = r
+		}
+		r.add(k.Model, usage)
+	}
+	result := make([]Row, 0, len(rows))
+	for _, r := range rows {
+		slices.Sort(r.Models)
+		slices.Sort(r.Unpriced)
+		result = append(result, *r)
+	}
+	switch opts.By {
+	case ByDay, ByWeek, ByMonth:
+		slices.SortFunc(result, func(a, b Row) int {
+			return strings.Compare(a.Group, b.Group)
+		})
+	default:
+		slices.SortFunc(result, func(a, b Row) int {
+			return cmp.Or(cmp.Compare(b.Cost, a.Cost), strings.Compare(a.Group, b.Group))
+		})
+		if opts.Top > 0 && len(result) > opts.Top {
+			other := Total(result[opts.Top:])
+			other.Group = "other"
+			result = append(result[:opts.Top], other)
+		}
+	}
+	return result, nil
+}
+
+// Total returns the sum of rows.
+func Total(rows []Row) Row {
+	total := Row{Group: "total"}
+	models := make(map[string]bool)
+	unpriced := make(map[string]bool)
+	for _, r := range rows {
+		for _, m := range r.Models {
+			models[m] = true
+		}
+		for _, m := range r.Unpriced {
+			unpriced[m] = true
+		}
+		total.InputTokens += r.InputTokens
+		total.OutputTokens += r.OutputTokens
+		total.CacheWriteTokens += r.CacheWriteTokens
+		total.CacheReadTokens += r.CacheReadTokens
+		total.TotalTokens += r.TotalTokens
+		total.WebSearchRequests += r.WebSearchRequests
+		total.WebFetchRequests += r.WebFetchRequests
+		total.Cost += r.Cost
+	}
+	total.Models = slices.Sorted(maps.Keys(models))
+	if len(unpriced) > 0 {
+		total.Unpriced = slices.Sorted(maps.Keys(unpriced))
+	}
+	return total
+}
+
+// add adds usage of the model, which is priced by its raw ID and listed by
+// its normalized one.
+func (r *Row) add(model string, usage transcript.Usage) {
+	id := pricing.Normalize(model).ID
+	if !slices.Contains(r.Models, id) {
+		r.Models = append(r.Models, id)
+	}
+	r.InputTokens += usage.InputTokens
+	r.OutputTokens += usage.OutputTokens
+	r.CacheWriteTokens += usage.CacheWriteTokens
+	r.CacheReadTokens += usage.CacheReadTokens
+	r.TotalTokens += usage.Total()
+	r.WebSearchRequests += usage.WebSearchRequests
+	r.WebFetchRequests += usage.WebFetchRequests
+	cost, ok := pricing.Cost(model, usage)
+	r.Cost += cost
+	if !ok && usage.Total() > 0 && !slices.Contains(r.Unpriced, id) {
+		r.Unpriced = append(r.Unpriced, id)
+	}
+}
+
+// cost formats the cost of the row, marked with "?" when it estimates or
+// leaves out models with unknown pricing.
+func (r Row) cost() string {
+	s := fmt.Sprintf("$%.2f", r.Cost)
+	if len(r.Unpriced) > 0 {
+		s += "?"
+	}
+	return s
+}
+
+func groupKey(opts Options) (func(transcript.Transcript, transcript.Event) string, error) {
+	loc := opts.Location
+	if loc == nil {
+		loc = time.Local
+	}
+	switch opts.By {
+	case ByDay:
+		return func(_ transcript.Transcript, e transcript.Event) string {
+			from, _ := transcript.Day(e.Timestamp.In(loc))
+			return from.Format(time.DateOnly)
+		}, nil
+	case ByWeek:
+		return func(_ transcript.Transcript, e transcript.Event) string {
+			from, _ := transcript.Week(e.Timestamp.In(loc), opts.WeekStart)
+			return from.Format(time.DateOnly)
+		}, nil
+	case ByMonth:
+		return func(_ transcript.Transcript, e transcript.Event) string {
+			return e.Timestamp.In(loc).Format("2006-01")
+		}, nil
+	case BySession:
+		return func(_ transcript.Transcript, e transcript.Event) string {
+			return e.SessionID
+		}, nil
+	case ByModel:
+		return func(_ transcript.Transcript, e transcript.Event) string {
+			return pricing.Normalize(e.Message.Model).ID
+		}, nil
+	case ByProject:
+		return func(t transcript.Transcript, _ transcript.Event) string {
+			return t.Project
+		}, nil
+	default:
+		return nil, fmt.Errorf("unknown grouping %q, expected one of %v", opts.By, Groupings)
+	}
+}
+
+// Write writes rows in the format. Text and Markdown tables end with a
+// total row.
+func Write(w io.Writer, rows []Row, format string) error {
+	switch format {
+	case FormatText:
+		return writeText(w, rows)
+	case FormatJSON:
+		enc := json.NewEncoder(w)
+		enc.SetIndent("", "  ")
+		return enc.Encode(rows)
+	case FormatCSV:
+		return writeCSV(w, rows)
+	case FormatMarkdown:
+		return writeMarkdown(w, rows)
+	default:
+		return fmt.Errorf("unknown format %q, expected one of %v", format, Formats)
+	}
+}
+
+var header = []string{"group", "models", "input", "output", "cache_write", "cache_read", "total", "web_search", "web_fetch", "cost"}
+
+func writeText(w io.Writer, rows []Row) error {
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', tabwriter.AlignRight)
+	line := func(cells ...string) {
+		_, _ = fmt.Fprintln(tw, strings.Join(cells, "\t")+"\t")
+	}
+	line(header...)
+	for _, r := range append(rows, Total(rows)) {
+		line(
+			r.Group,
+			strings.Join(r.Models, ","),
+			strconv.Itoa(r.InputTokens),
+			strconv.Itoa(r.OutputTokens),
+			strconv.Itoa(r.CacheWriteTokens),
+			strconv.Itoa(r.CacheReadTokens),
+			strconv.Itoa(r.TotalTokens),
+			strconv.Itoa(r.WebSearchRequests),
+			strconv.Itoa(r.WebFetchRequests),
+			r.cost(),
+		)
+	}
+	return tw.Flush()
+}
+
+func writeCSV(w io.Writer, rows []Row) error {
+	cw := csv.NewWriter(w)
+	if err := cw.Write(header); err != nil {
+		return err
+	}
+	for _, r := range rows {
+		if err := cw.Write([]string{
+			r.Group,
+			strings.Join(r.Models, ","),
+			strconv.Itoa(r.InputTokens),
+			strconv.Itoa(r.OutputTokens),
+			strconv.Itoa(r.CacheWriteTokens),
+			strconv.Itoa(r.CacheReadTokens),
+			strconv.Itoa(r.TotalTokens),
+			strconv.Itoa(r.WebSearchRequests),
+			strconv.Itoa(r.WebFetchRequests),
+			strconv.FormatFloat(r.Cost, 'f', 6, 64),
+		}); err != nil {
+			return err
+		}
+	}
+	cw.Flush()
+	return cw.Error()
+}
+
+func writeMarkdown(w io.Writer, rows []Row) error {
+	var b strings.Builder
+	b.WriteString("| Group | Models | Input | Output | Cache write | Cache read | Total | Web searches | Web fetches | Cost |\n")
+	b.WriteString("|---|---|--:|--:|--:|--:|--:|--:|--:|--:|\n")
+	line := func(r Row, emphasis string) {
+		cells := []string{
+			r.Group,
+			strings.Join(r.Models, ", "),
+			strconv.Itoa(r.InputTokens),
+			strconv.Itoa(r.OutputTokens),
+			strconv.Itoa(r.CacheWriteTokens),
+			strconv.Itoa(r.CacheReadTokens),
+			strconv.Itoa(r.TotalTokens),
+			strconv.Itoa(r.WebSearchRequests),
+			strconv.Itoa(r.WebFetchRequests),
+			r.cost(),
+		}
+		for i, c := range cells {
+			if c != "" {
+				cells[i] = emphasis + strings.ReplaceAll(c, "|", "\\|") + emphasis
+			}
+		}
+		b.WriteString("| " + strings.Join(cells, " | ") + " |\n")
+	}
+	for _, r := range rows {
+		line(r, "")
+	}
+	total := Total(rows)
+	total.Models = nil
+	line(total, "**")
+	_, err := io.WriteString(w, b.String())
+	return err
+}
@@ -0,0 +1,162 @@
+package report_test
+
+import (
+	"bytes"
+	"encoding/json"
+	"math"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/iskorotkov/cc-statusline/report"
+	"github.com/iskorotkov/cc-statusline/transcript"
+)
+
+func testTranscripts() []transcript.Transcript {
+	event := func(session, model string, at time.Time, tokens int) transcript.Event {
+		e := transcript.Event{SessionID: session, Timestamp: at, GitBranch: "main"}
+		if session == "s2" {
+			e.GitBranch = "feature"
+		}
+		e.Message.ID = session + at.String()
+		e.Message.Model = model
+		e.Message.Usage.InputTokens = tokens
+		return e
+	}
+	day := func(d, h int) time.Time {
+		return time.Date(2025, 1, d, h, 0, 0, 0, time.UTC)
+	}
+	return []transcript.Transcript{
+		{Project: "/src/a", Events: []transcript.Event{
+			event("s1", "claude-sonnet-4-20250514", day(1, 10), 100_000),
+			event("s1", "claude-sonnet-4-20250514", day(1, 23), 100_000),
+			event("s1", "claude-sonnet-4-20250514", day(7, 22), 100_000),
+		}},
+		{Project: "/src/b", Events: []transcript.Event{
+			event("s2", "claude-opus-4-1-20250805", day(2, 10), 100_000),
+			event("s2", "claude-sonnet-4-20250514", day(8, 10), 100_000),
+		}},
+		{Project: "/src/c", Events: []transcript.Event{
+			event("s3", "claude-sonnet-4-20250514", day(8, 11), 100_000),
+		}},
+	}
+}
+
+func TestBuild(t *testing.T) {
+	msk := time.FixedZone("UTC+3", 3*60*60)
+	tests := []struct {
+		name string
+		opts report.Options
+		want []string
+	}{
+		{name: "day", opts: report.Options{By: report.ByDay, Location: time.UTC}, want: []string{"2025-01-01", "2025-01-02", "2025-01-07", "2025-01-08"}},
+		{name: "day in timezone", opts: report.Options{By: report.ByDay, Location: msk}, want: []string{"2025-01-01", "2025-01-02", "2025-01-08"}},
+		{name: "week", opts: report.Options{By: report.ByWeek, Location: time.UTC, WeekStart: time.Monday}, want: []string{"2024-12-30", "2025-01-06"}},
+		{name: "month", opts: report.Options{By: report.ByMonth, Location: time.UTC}, want: []string{"2025-01"}},
+		{name: "session", opts: report.Options{By: report.BySession}, want: []string{"s2", "s1", "s3"}},
+		{name: "model", opts: report.Options{By: report.ByModel}, want: []string{"claude-opus-4-1-20250805", "claude-sonnet-4-20250514"}},
+		{name: "project", opts: report.Options{By: report.ByProject}, want: []string{"/src/b", "/src/a", "/src/c"}},
+		{name: "branch", opts: report.Options{By: report.BySession, Branch: "feature"}, want: []string{"s2"}},
+		{name: "project top", opts: report.Options{By: report.ByProject, Top: 1}, want: []string{"/src/b", "other"}},
+		{
+			name: "since until",
+			opts: report.Options{
+				By:       report.ByDay,
+				Location: time.UTC,
+				Since:    time.Date(2025, 1, 1, 12, 0, 0, 0, time.UTC),
+				Until:    time.Date(2025, 1, 8, 0, 0, 0, 0, time.UTC),
+			},
+			want: []string{"2025-01-01", "2025-01-02", "2025-01-07"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rows, err := report.Build(testTranscripts(), tt.opts)
+			if err != nil {
+				t.Fatalf("Build() error: %v", err)
+			}
+			var groups []string
+			for _, r := range rows {
+				groups = append(groups, r.Group)
+			}
+			if strings.Join(groups, " ") != strings.Join(tt.want, " ") {
+				t.Errorf("groups = %v, want %v", groups, tt.want)
+			}
+		})
+	}
+	transcripts := testTranscripts()
+	transcripts[2].Events[0].Message.Model = "us.anthropic.claude-sonnet-4-20250514-v1:0"
+	rows, err := report.Build(transcripts, report.Options{By: report.ByModel})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(rows) != 2 || rows[1].Group != "claude-sonnet-4-20250514" || rows[1].InputTokens != 500_000 {
+		t.Errorf("rows with a Bedrock model = %+v, want it merged with claude-sonnet-4-20250514", rows)
+	}
+
+	if _, err := report.Build(testTranscripts(), report.Options{By: "year"}); err == nil {
+		t.Error("Build() with unknown grouping succeeded, want error")
+	}
+}
+
+func TestWrite(t *testing.T) {
+	rows, err := report.Build(testTranscripts(), report.Options{By: report.ByModel})
+	if err != nil {
+		t.Fatal(err)
+	}
+	total := report.Total(rows)
+	if total.InputTokens != 600_000 || math.Abs(total.Cost-3) > 1e-9 {
+		t.Errorf("total = %+v, want 600K input tokens and $3", total)
+	}
+
+	var b bytes.Buffer
+	if err := report.Write(&b, rows, report.FormatText); err != nil {
+		t.Fatalf("Write(text) error: %v", err)
+	}
+	if lines := strings.Split(strings.TrimSpace(b.String()), "\n"); len(lines) != 4 || !strings.Contains(lines[3], "$3.00") {
+		t.Errorf("text output = %q, want header, 2 rows and total", b.String())
+	}
+
+	b.Reset()
+	if err := report.Write(&b, rows, report.FormatJSON); err != nil {
+		t.Fatalf("Write(json) error: %v", err)
+	}
+	var decoded []report.Row
+	if err := json.Unmarshal(b.Bytes(), &decoded); err != nil || len(decoded) != 2 {
+		t.Errorf("json output = %q, err %v, want 2 rows", b.String(), err)
+	}
+
+	b.Reset()
+	if err := report.Write(&b, rows, report.FormatCSV); err != nil {
+		t.Fatalf("Write(csv) error: %v", err)
+	}
+	if !strings.HasPrefix(b.String(), "group,models,") || strings.Count(b.String(), "\n") != 3 {
+		t.Errorf("csv output = %q, want header and 2 rows", b.String())
+	}
+
+	b.Reset()
+	if err := report.Write(&b, rows, report.FormatMarkdown); err != nil {
+		t.Fatalf("Write(markdown) error: %v", err)
+	}
+	if lines := strings.Split(strings.TrimSpace(b.String()), "\n"); len(lines) != 5 || !strings.Contains(lines[4], "**$3.00**") {
+		t.Errorf("markdown output = %q, want header, separator, 2 rows and total", b.String())
+	}
+
+	if err := report.Write(&b, rows, "xml"); err == nil {
+		t.Error("Write() with unknown format succeeded, want error")
+	}
+	transcripts := testTranscripts()
+	transcripts[2].Events[0].Message.Model = "gpt-5"
+	// Models only known by family are priced as estimates.
+	transcripts[0].Events[0].Message.Model = "claude-sonnet-5"
+	if rows, err = report.Build(transcripts, report.Options{By: report.ByProject}); err != nil {
+		t.Fatal(err)
+	}
+	b.Reset()
+	if err := report.Write(&b, rows, report.FormatText); err != nil {
+		t.Fatalf("Write(text) error: %v", err)
+	}
+	if lines := strings.Split(strings.TrimSpace(b.String()), "\n"); !strings.Contains(lines[2], "$0.90?") || !strings.Contains(lines[3], "$0.00?") || !strings.Contains(lines[4], "$2.70?") {
+		t.Errorf("text output = %q, want costs of unpriced rows marked", b.String())
+	}
+}
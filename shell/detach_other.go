@@ -0,0 +1,11 @@
+//go:build !unix && !windows
+
+package shell
+
+import "os/exec"
+
+func detach(cmd *exec.Cmd) {}
+
+func command(s string) *exec.Cmd {
+	return exec.Command("sh", "-c", s)
+}
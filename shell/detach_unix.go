@@ -0,0 +1,17 @@
+//go:build unix
+
+package shell
+
+import (
+	"os/exec"
+	"syscall"
+)
+
+// detach runs cmd in a new session so it outlives the statusline process.
+func detach(cmd *exec.Cmd) {
+	cmd.SysProcAttr = &syscall.SysProcAttr{Setsid: true}
+}
+
+func command(s string) *exec.Cmd {
+	return exec.Command("sh", "-c", s)
+}
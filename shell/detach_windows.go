@@ -0,0 +1,22 @@
+//go:build windows
+
+package shell
+
+import (
+	"os/exec"
+	"syscall"
+)
+
+const (
+	detachedProcess       = 0x00000008
+	createNewProcessGroup = 0x00000200
+)
+
+// detach runs cmd without a console so it outlives the statusline process.
+func detach(cmd *exec.Cmd) {
+	cmd.SysProcAttr = &syscall.SysProcAttr{CreationFlags: detachedProcess | createNewProcessGroup}
+}
+
+func command(s string) *exec.Cmd {
+	return exec.Command("cmd", "/C", s)
+}
@@ -29,3 +29,18 @@ func JSON[T any](ctx context.Context, s ...string) (T, error) {
 	}
 	return result, nil
 }
+
+// Command returns a command running s with the system shell.
+func Command(s string) *exec.Cmd {
+	return command(s)
+}
+
+// Start starts cmd detached from the statusline process, so that it keeps
+// running after the statusline exits.
+func Start(cmd *exec.Cmd) error {
+	detach(cmd)
+	if err := cmd.Start(); err != nil {
+		return err
+	}
+	return cmd.Process.Release()
+}
@@ -42,6 +42,10 @@ func Green(text string) string {
 	return themeGreen(text)
 }
 
+func Yellow(text string) string {
+	return themeYellow(text)
+}
+
 func themeBlue(text string) string {
 	theme := detectTheme()
 	switch theme {
@@ -72,6 +76,16 @@ func themeGreen(text string) string {
 	}
 }
 
+func themeYellow(text string) string {
+	theme := detectTheme()
+	switch theme {
+	case themeLight:
+		return rgb(text, 160, 112, 0)
+	default:
+		return rgb(text, 255, 223, 127)
+	}
+}
+
 func detectTheme() theme {
 	if ccTheme := os.Getenv("CC_THEME"); ccTheme != "" {
 		switch strings.ToLower(ccTheme) {
@@ -0,0 +1,62 @@
+package transcript
+
+import (
+	"slices"
+	"time"
+)
+
+// BlockDuration is the length of a billing block of Claude subscriptions.
+const BlockDuration = 5 * time.Hour
+
+// Block is a billing block. It starts at the hour of the first message sent
+// after the previous block ended, and lasts BlockDuration.
+type Block struct {
+	Start time.Time
+	End   time.Time
+	// LastEvent is the time of the last message in the block.
+	LastEvent time.Time
+	Usage     map[string]Usage
+}
+
+// Blocks returns billing blocks with messages from transcripts, ordered by
+// start time. Periods without messages longer than a block are gaps and
+// have no blocks.
+func Blocks(transcripts []Transcript) []Block {
+	events := slices.Collect(deduplicateEvents(transcripts, nil))
+	slices.SortStableFunc(events, func(a, b Event) int {
+		return a.Timestamp.Compare(b.Timestamp)
+	})
+	var blocks []Block
+	for _, e := range events {
+		if e.Timestamp.IsZero() {
+			continue
+		}
+		if len(blocks) == 0 || !e.Timestamp.Before(blocks[len(blocks)-1].End) {
+			start := e.Timestamp.Truncate(time.Hour)
+			blocks = append(blocks, Block{
+				Start: start,
+				End:   start.Add(BlockDuration),
+				Usage: make(map[string]Usage),
+			})
+		}
+		b := &blocks[len(blocks)-1]
+		b.LastEvent = e.Timestamp
+		usage := b.Usage[e.Message.Model]
+		usage.Add(e.Message.Usage)
+		b.Usage[e.Message.Model] = usage
+	}
+	return blocks
+}
+
+// ActiveBlock returns the block containing now, if any.
+func ActiveBlock(transcripts []Transcript, now time.Time) (Block, bool) {
+	blocks := Blocks(transcripts)
+	if len(blocks) == 0 {
+		return Block{}, false
+	}
+	b := blocks[len(blocks)-1]
+	if now.Before(b.Start) || !now.Before(b.End) {
+		return Block{}, false
+	}
+	return b, true
+}
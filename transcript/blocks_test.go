@@ -0,0 +1,57 @@
+package transcript_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/iskorotkov/cc-statusline/transcript"
+)
+
+func TestBlocks(t *testing.T) {
+	start := time.Date(2025, 1, 1, 10, 0, 0, 0, time.UTC)
+	event := func(id string, at time.Duration) transcript.Event {
+		e := transcript.Event{Timestamp: start.Add(at)}
+		e.Message.ID = id
+		e.Message.Model = "claude-sonnet-4-20250514"
+		e.Message.Usage.InputTokens = 1
+		return e
+	}
+	transcripts := []transcript.Transcript{
+		{Events: []transcript.Event{
+			event("m1", 20*time.Minute),
+			event("m3", 5*time.Hour+10*time.Minute),
+		}},
+		{Events: []transcript.Event{
+			event("m2", 4*time.Hour),
+			event("m4", 14*time.Hour+30*time.Minute),
+		}},
+	}
+	blocks := transcript.Blocks(transcripts)
+	want := []struct {
+		start  time.Duration
+		tokens int
+	}{
+		{start: 0, tokens: 2},
+		{start: 5 * time.Hour, tokens: 1},
+		{start: 14 * time.Hour, tokens: 1},
+	}
+	if len(blocks) != len(want) {
+		t.Fatalf("got %d blocks, want %d", len(blocks), len(want))
+	}
+	for i, w := range want {
+		b := blocks[i]
+		if !b.Start.Equal(start.Add(w.start)) || !b.End.Equal(b.Start.Add(transcript.BlockDuration)) {
+			t.Errorf("block %d = [%v, %v), want start %v", i, b.Start, b.End, start.Add(w.start))
+		}
+		if got := b.Usage["claude-sonnet-4-20250514"].InputTokens; got != w.tokens {
+			t.Errorf("block %d input tokens = %d, want %d", i, got, w.tokens)
+		}
+	}
+
+	if b, ok := transcript.ActiveBlock(transcripts, start.Add(15*time.Hour)); !ok || !b.Start.Equal(start.Add(14*time.Hour)) {
+		t.Errorf("ActiveBlock() = %v, %v, want block at %v", b.Start, ok, start.Add(14*time.Hour))
+	}
+	if _, ok := transcript.ActiveBlock(transcripts, start.Add(19*time.Hour)); ok {
+		t.Error("ActiveBlock() after the last block ended found a block")
+	}
+}
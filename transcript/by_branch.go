@@ -0,0 +1,17 @@
+package transcript
+
+// BranchUsage returns usage of events recorded on the git branch, by model.
+// Branch names are not unique across repos, so transcripts should be
+// filtered by project first.
+func BranchUsage(transcripts []Transcript, branch string) map[string]Usage {
+	usages := make(map[string]Usage)
+	onBranch := func(e Event) bool {
+		return e.GitBranch == branch
+	}
+	for e := range deduplicateEvents(transcripts, onBranch) {
+		usage := usages[e.Message.Model]
+		usage.Add(e.Message.Usage)
+		usages[e.Message.Model] = usage
+	}
+	return usages
+}
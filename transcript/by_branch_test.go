@@ -0,0 +1,26 @@
+package transcript_test
+
+import (
+	"testing"
+
+	"github.com/iskorotkov/cc-statusline/transcript"
+)
+
+func TestBranchUsage(t *testing.T) {
+	transcripts := resumedTranscripts()
+	for i := range transcripts {
+		for j := range transcripts[i].Events {
+			e := &transcripts[i].Events[j]
+			e.GitBranch = "main"
+			if e.Message.ID == "m3" {
+				e.GitBranch = "feature"
+			}
+		}
+	}
+	for branch, want := range map[string]int{"main": 30, "feature": 120, "other": 0} {
+		usage := transcript.BranchUsage(transcripts, branch)
+		if got := usage["claude-sonnet-4-20250514"].InputTokens; got != want {
+			t.Errorf("branch %s input tokens = %d, want %d", branch, got, want)
+		}
+	}
+}
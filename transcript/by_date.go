@@ -10,70 +10,117 @@ type TimeModel struct {
 	Model string
 }
 
+// LongContextTokens is the number of input tokens above which requests are
+// billed at long context rates.
+const LongContextTokens = 200_000
+
 type Usage struct {
 	InputTokens      int
 	OutputTokens     int
 	CacheWriteTokens int
 	CacheReadTokens  int
+	// CacheWrite1hTokens is the part of CacheWriteTokens written to the
+	// 1-hour cache, which is billed at a higher rate than the 5-minute one.
+	CacheWrite1hTokens int
+	// LongContext is the part of the usage by requests with more than
+	// LongContextTokens of input.
+	LongContext Tokens
+	// WebSearchRequests and WebFetchRequests count server tool requests.
+	WebSearchRequests int
+	WebFetchRequests  int
+}
+
+// Tokens are token counts of a subset of Usage.
+type Tokens struct {
+	InputTokens        int
+	OutputTokens       int
+	CacheWriteTokens   int
+	CacheWrite1hTokens int
+	CacheReadTokens    int
 }
 
 func (u Usage) Total() int {
 	return u.InputTokens + u.OutputTokens + u.CacheWriteTokens + u.CacheReadTokens
 }
 
+// Add adds usage of a request. Requests over LongContextTokens of input are
+// also added to LongContext, since they are priced differently.
 func (u *Usage) Add(e EventUsage) {
 	u.InputTokens += e.InputTokens
 	u.OutputTokens += e.OutputTokens
 	u.CacheWriteTokens += e.CacheCreationInputTokens
 	u.CacheReadTokens += e.CacheReadInputTokens
+	u.CacheWrite1hTokens += e.CacheCreation.Ephemeral1hInputTokens
+	u.WebSearchRequests += e.ServerToolUse.WebSearchRequests
+	u.WebFetchRequests += e.ServerToolUse.WebFetchRequests
+	if e.Input() > LongContextTokens {
+		u.LongContext.InputTokens += e.InputTokens
+		u.LongContext.OutputTokens += e.OutputTokens
+		u.LongContext.CacheWriteTokens += e.CacheCreationInputTokens
+		u.LongContext.CacheWrite1hTokens += e.CacheCreation.Ephemeral1hInputTokens
+		u.LongContext.CacheReadTokens += e.CacheReadInputTokens
+	}
+}
+
+// Merge adds usage v to u.
+func (u *Usage) Merge(v Usage) {
+	u.InputTokens += v.InputTokens
+	u.OutputTokens += v.OutputTokens
+	u.CacheWriteTokens += v.CacheWriteTokens
+	u.CacheReadTokens += v.CacheReadTokens
+	u.CacheWrite1hTokens += v.CacheWrite1hTokens
+	u.WebSearchRequests += v.WebSearchRequests
+	u.WebFetchRequests += v.WebFetchRequests
+	u.LongContext.InputTokens += v.LongContext.InputTokens
+	u.LongContext.OutputTokens += v.LongContext.OutputTokens
+	u.LongContext.CacheWriteTokens += v.LongContext.CacheWriteTokens
+	u.LongContext.CacheWrite1hTokens += v.LongContext.CacheWrite1hTokens
+	u.LongContext.CacheReadTokens += v.LongContext.CacheReadTokens
 }
 
 func DateUsage(transcripts []Transcript, from, to time.Time) map[string]Usage {
 	usages := make(map[string]Usage)
-	for _, t := range transcripts {
-		for e := range deduplicateEvents(t.Events) {
-			if e.Timestamp.Before(from) || e.Timestamp.Equal(to) || e.Timestamp.After(to) {
-				continue
-			}
-			usage := usages[e.Message.Model]
-			usage.Add(e.Message.Usage)
-			usages[e.Message.Model] = usage
-		}
+	inRange := func(e Event) bool {
+		return !e.Timestamp.Before(from) && e.Timestamp.Before(to)
+	}
+	for e := range deduplicateEvents(transcripts, inRange) {
+		usage := usages[e.Message.Model]
+		usage.Add(e.Message.Usage)
+		usages[e.Message.Model] = usage
 	}
 	return usages
 }
 
-func UsageByDate(transcripts []Transcript) map[TimeModel]Usage {
+// UsageByDate returns usage by model and by day, with days starting at
+// midnight in loc.
+func UsageByDate(transcripts []Transcript, loc *time.Location) map[TimeModel]Usage {
 	usages := make(map[TimeModel]Usage)
-	for _, t := range transcripts {
-		for e := range deduplicateEvents(t.Events) {
-			key := TimeModel{
-				Date:  e.Timestamp.Truncate(24 * time.Hour),
-				Model: e.Message.Model,
-			}
-			usage := usages[key]
-			usage.InputTokens += e.Message.Usage.InputTokens
-			usage.OutputTokens += e.Message.Usage.OutputTokens
-			usage.CacheWriteTokens += e.Message.Usage.CacheCreationInputTokens
-			usage.CacheReadTokens += e.Message.Usage.CacheReadInputTokens
-			usages[key] = usage
+	for e := range deduplicateEvents(transcripts, nil) {
+		day, _ := Day(e.Timestamp.In(loc))
+		key := TimeModel{
+			Date:  day,
+			Model: e.Message.Model,
 		}
+		usage := usages[key]
+		usage.Add(e.Message.Usage)
+		usages[key] = usage
 	}
 	return usages
 }
 
-func deduplicateEvents(events []Event) iter.Seq[Event] {
+// deduplicateEvents yields events of all transcripts that match, or all
+// events if match is nil, skipping repeated messages. Claude Code writes a
+// message once per content block, and copies earlier messages into a new
+// file when a session is resumed or forked, so a message is identified by
+// its ID and the ID of the API request.
+func deduplicateEvents(transcripts []Transcript, match func(Event) bool) iter.Seq[Event] {
 	return func(yield func(Event) bool) {
-		seen := make(map[string]bool)
-		for _, e := range events {
-			if e.Message.ID == "" {
-				if !yield(e) {
-					return
+		seen := make(seenEvents)
+		for _, t := range transcripts {
+			for _, e := range t.Events {
+				if match != nil && !match(e) || !seen.add(e) {
+					continue
 				}
-				continue
-			}
-			if !seen[e.Message.ID] {
-				seen[e.Message.ID] = true
 				if !yield(e) {
 					return
 				}
@@ -81,3 +128,24 @@ func deduplicateEvents(events []Event) iter.Seq[Event] {
 		}
 	}
 }
+
+type eventKey struct {
+	MessageID string
+	RequestID string
+}
+
+type seenEvents map[eventKey]bool
+
+// add reports whether the event was not seen before. Events without a
+// message ID are never considered repeated.
+func (s seenEvents) add(e Event) bool {
+	if e.Message.ID == "" {
+		return true
+	}
+	key := eventKey{MessageID: e.Message.ID, RequestID: e.RequestID}
+	if s[key] {
+		return false
+	}
+	s[key] = true
+	return true
+}
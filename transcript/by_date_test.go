@@ -1,6 +1,7 @@
 package transcript_test
 
 import (
+	"encoding/json"
 	"os"
 	"testing"
 	"time"
@@ -16,7 +17,7 @@ func TestDateUsage(t *testing.T) {
 	if err != nil {
 		t.Fatal(err)
 	}
-	sessions := transcript.UsageByDate(transcripts)
+	sessions := transcript.UsageByDate(transcripts, time.Local)
 	if len(sessions) == 0 {
 		t.Skipf("no sessions found")
 	}
@@ -44,9 +45,9 @@ func TestUsageByDate(t *testing.T) {
 	if err != nil {
 		t.Fatalf("ParseTranscripts() error: %v", err)
 	}
-	usages := transcript.UsageByDate(transcripts)
+	usages := transcript.UsageByDate(transcripts, time.Local)
 	for k, usage := range usages {
-		if !k.Date.Equal(k.Date.Truncate(24 * time.Hour)) {
+		if day, _ := transcript.Day(k.Date); !k.Date.Equal(day) {
 			t.Errorf("date %v is not truncated to day", k.Date)
 		}
 		if k.Date.Before(time.Date(2023, 1, 1, 0, 0, 0, 0, time.UTC)) {
@@ -63,3 +64,50 @@ func TestUsageByDate(t *testing.T) {
 		}
 	}
 }
+
+func TestDateUsageResumed(t *testing.T) {
+	transcripts := resumedTranscripts()
+	from := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+	usage := transcript.DateUsage(transcripts, from, from.Add(24*time.Hour))
+	if got := usage["claude-sonnet-4-20250514"].InputTokens; got != 150 {
+		t.Errorf("input tokens = %d, want 150", got)
+	}
+	byDate := transcript.UsageByDate(transcripts, time.UTC)
+	key := transcript.TimeModel{Date: from, Model: "claude-sonnet-4-20250514"}
+	if got := byDate[key].InputTokens; got != 150 {
+		t.Errorf("input tokens by date = %d, want 150", got)
+	}
+}
+
+func TestUsageAdd(t *testing.T) {
+	var small, long transcript.EventUsage
+	if err := json.Unmarshal([]byte(`{"input_tokens":10,"cache_creation_input_tokens":300,"cache_read_input_tokens":1000,"output_tokens":50,`+
+		`"cache_creation":{"ephemeral_5m_input_tokens":100,"ephemeral_1h_input_tokens":200},`+
+		`"server_tool_use":{"web_search_requests":2,"web_fetch_requests":1}}`), &small); err != nil {
+		t.Fatal(err)
+	}
+	if err := json.Unmarshal([]byte(`{"input_tokens":5,"cache_creation_input_tokens":1000,"cache_read_input_tokens":250000,"output_tokens":20}`), &long); err != nil {
+		t.Fatal(err)
+	}
+	var usage transcript.Usage
+	usage.Add(small)
+	usage.Add(long)
+	want := transcript.Usage{
+		InputTokens:        15,
+		OutputTokens:       70,
+		CacheWriteTokens:   1300,
+		CacheReadTokens:    251000,
+		CacheWrite1hTokens: 200,
+		WebSearchRequests:  2,
+		WebFetchRequests:   1,
+		LongContext: transcript.Tokens{
+			InputTokens:      5,
+			OutputTokens:     20,
+			CacheWriteTokens: 1000,
+			CacheReadTokens:  250000,
+		},
+	}
+	if usage != want {
+		t.Errorf("usage = %+v, want %+v", usage, want)
+	}
+}
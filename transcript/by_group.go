@@ -0,0 +1,28 @@
+package transcript
+
+// GroupModel identifies usage of a model within a group of events.
+type GroupModel struct {
+	Group string
+	Model string
+}
+
+// UsageByGroup returns usage by model and by the group key returns for each
+// event and its transcript. Events for which key returns an empty group are
+// skipped.
+func UsageByGroup(transcripts []Transcript, key func(Transcript, Event) string) map[GroupModel]Usage {
+	usages := make(map[GroupModel]Usage)
+	seen := make(seenEvents)
+	for _, t := range transcripts {
+		for _, e := range t.Events {
+			group := key(t, e)
+			if group == "" || !seen.add(e) {
+				continue
+			}
+			k := GroupModel{Group: group, Model: e.Message.Model}
+			usage := usages[k]
+			usage.Add(e.Message.Usage)
+			usages[k] = usage
+		}
+	}
+	return usages
+}
@@ -0,0 +1,27 @@
+package transcript_test
+
+import (
+	"testing"
+
+	"github.com/iskorotkov/cc-statusline/transcript"
+)
+
+func TestUsageByGroup(t *testing.T) {
+	transcripts := resumedTranscripts()
+	usages := transcript.UsageByGroup(transcripts, func(_ transcript.Transcript, e transcript.Event) string {
+		if e.Message.ID == "m2" {
+			return ""
+		}
+		return e.SessionID
+	})
+	want := map[string]int{"s1": 10, "s2": 120}
+	if len(usages) != len(want) {
+		t.Errorf("got %d groups, want %d: %v", len(usages), len(want), usages)
+	}
+	for session, tokens := range want {
+		k := transcript.GroupModel{Group: session, Model: "claude-sonnet-4-20250514"}
+		if got := usages[k].InputTokens; got != tokens {
+			t.Errorf("group %s input tokens = %d, want %d", session, got, tokens)
+		}
+	}
+}
@@ -19,15 +19,13 @@ func Sessions(transcripts []Transcript) []string {
 
 func SessionUsage(transcripts []Transcript, sessionID string) map[string]Usage {
 	usages := make(map[string]Usage)
-	for _, t := range transcripts {
-		for e := range deduplicateEvents(t.Events) {
-			if e.SessionID != sessionID {
-				continue
-			}
-			usage := usages[e.Message.Model]
-			usage.Add(e.Message.Usage)
-			usages[e.Message.Model] = usage
-		}
+	inSession := func(e Event) bool {
+		return e.SessionID == sessionID
+	}
+	for e := range deduplicateEvents(transcripts, inSession) {
+		usage := usages[e.Message.Model]
+		usage.Add(e.Message.Usage)
+		usages[e.Message.Model] = usage
 	}
 	return usages
 }
@@ -3,6 +3,7 @@ package transcript_test
 import (
 	"os"
 	"testing"
+	"time"
 
 	"github.com/iskorotkov/cc-statusline/transcript"
 )
@@ -55,3 +56,45 @@ func TestSessionUsage(t *testing.T) {
 		}
 	}
 }
+
+// resumedTranscripts returns a session s1 and its resumed copy s2. Message
+// m1 is written once per content block, and s2 starts with a copy of s1.
+func resumedTranscripts() []transcript.Transcript {
+	event := func(session, message, request string, at time.Time, tokens int) transcript.Event {
+		e := transcript.Event{SessionID: session, RequestID: request, Timestamp: at}
+		e.Message.ID = message
+		e.Message.Model = "claude-sonnet-4-20250514"
+		e.Message.Usage.InputTokens = tokens
+		return e
+	}
+	start := time.Date(2025, 1, 1, 10, 0, 0, 0, time.UTC)
+	return []transcript.Transcript{
+		{
+			File: "-project/s1.jsonl",
+			Events: []transcript.Event{
+				event("s1", "m1", "r1", start, 10),
+				event("s1", "m1", "r1", start, 10),
+				event("s1", "m2", "r2", start.Add(time.Minute), 20),
+			},
+		},
+		{
+			File: "-project/s2.jsonl",
+			Events: []transcript.Event{
+				event("s1", "m1", "r1", start, 10),
+				event("s1", "m2", "r2", start.Add(time.Minute), 20),
+				event("s2", "m3", "r3", start.Add(time.Hour), 40),
+				event("s2", "m3", "r4", start.Add(time.Hour), 80),
+			},
+		},
+	}
+}
+
+func TestSessionUsageResumed(t *testing.T) {
+	transcripts := resumedTranscripts()
+	for session, want := range map[string]int{"s1": 30, "s2": 120} {
+		usage := transcript.SessionUsage(transcripts, session)
+		if got := usage["claude-sonnet-4-20250514"].InputTokens; got != want {
+			t.Errorf("session %s input tokens = %d, want %d", session, got, want)
+		}
+	}
+}
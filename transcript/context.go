@@ -0,0 +1,23 @@
+package transcript
+
+// Context returns the size of the session context, which is the input and
+// cache tokens of the latest message of the main thread, and the model of
+// that message. Messages of subagents have their own contexts and are
+// ignored.
+func Context(transcripts []Transcript, sessionID string) (tokens int, model string, ok bool) {
+	var latest Event
+	for _, t := range transcripts {
+		for _, e := range t.Events {
+			if e.SessionID != sessionID || e.IsSidechain || e.Message.Usage == (EventUsage{}) {
+				continue
+			}
+			if !ok || !e.Timestamp.Before(latest.Timestamp) {
+				latest, ok = e, true
+			}
+		}
+	}
+	if !ok {
+		return 0, "", false
+	}
+	return latest.Message.Usage.Input(), latest.Message.Model, true
+}
@@ -0,0 +1,39 @@
+package transcript_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/iskorotkov/cc-statusline/transcript"
+)
+
+func TestContext(t *testing.T) {
+	start := time.Date(2025, 1, 1, 10, 0, 0, 0, time.UTC)
+	event := func(session string, at time.Duration, sidechain bool, input, cacheRead, output int) transcript.Event {
+		e := transcript.Event{SessionID: session, Timestamp: start.Add(at), IsSidechain: sidechain}
+		e.Message.Model = "claude-sonnet-4-20250514"
+		e.Message.Usage.InputTokens = input
+		e.Message.Usage.CacheReadInputTokens = cacheRead
+		e.Message.Usage.OutputTokens = output
+		return e
+	}
+	transcripts := []transcript.Transcript{
+		{Events: []transcript.Event{
+			event("s1", 0, false, 10, 1000, 5),
+			event("s1", 2*time.Minute, false, 20, 3000, 5),
+			event("s1", 3*time.Minute, false, 0, 0, 0),
+		}},
+		{Events: []transcript.Event{
+			event("s1", time.Minute, false, 10, 2000, 5),
+			event("s1", 4*time.Minute, true, 500, 0, 5),
+			event("s2", 5*time.Minute, false, 7, 0, 5),
+		}},
+	}
+	tokens, model, ok := transcript.Context(transcripts, "s1")
+	if !ok || tokens != 3020 || model != "claude-sonnet-4-20250514" {
+		t.Errorf("Context() = %d, %q, %v, want 3020, claude-sonnet-4-20250514, true", tokens, model, ok)
+	}
+	if _, _, ok := transcript.Context(transcripts, "s3"); ok {
+		t.Error("Context() of a session without messages found a context")
+	}
+}
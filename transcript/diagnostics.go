@@ -0,0 +1,34 @@
+package transcript
+
+// Diagnostics summarizes problems found while parsing transcripts.
+type Diagnostics struct {
+	// SkippedLines is the number of lines that are not valid JSON, by file.
+	SkippedLines map[string]int
+	// PendingBytes is the size of unterminated last lines, by file.
+	PendingBytes map[string]int64
+}
+
+func Diagnose(transcripts []Transcript) Diagnostics {
+	d := Diagnostics{
+		SkippedLines: make(map[string]int),
+		PendingBytes: make(map[string]int64),
+	}
+	for _, t := range transcripts {
+		if t.SkippedLines > 0 {
+			d.SkippedLines[t.File] = t.SkippedLines
+		}
+		if t.PendingBytes > 0 {
+			d.PendingBytes[t.File] = t.PendingBytes
+		}
+	}
+	return d
+}
+
+// Skipped returns the total number of skipped lines.
+func (d Diagnostics) Skipped() int {
+	var total int
+	for _, n := range d.SkippedLines {
+		total += n
+	}
+	return total
+}
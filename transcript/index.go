@@ -0,0 +1,239 @@
+package transcript
+
+import (
+	"crypto/sha256"
+	"encoding/gob"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/iskorotkov/cc-statusline/dirs"
+)
+
+// indexVersion must be bumped whenever the index layout or the parsed event
+// fields change, so that stale indexes are rebuilt.
+const indexVersion = 7
+
+// index remembers parsed transcript files between runs, so that only lines
+// appended since the last run have to be parsed. Every file has its own
+// entry, written as soon as the file is parsed, so work done before a run
+// is cut short is kept, and reading one project dir only decodes the
+// entries of that dir. Entries mirror the projects dir of their root.
+//
+// Entries keep the usage events of files rather than usage aggregated per
+// file. Resumed sessions copy messages into new files, and only events
+// carry the message and request IDs needed to count them once across files.
+// Events also carry the session, branch, working dir and time that session,
+// branch, project, block and context parts group usage by. Events only hold
+// these fields, so entries stay much smaller than the transcripts.
+type index struct {
+	// dir is the dir of the index, empty if the cache dir is unavailable.
+	dir string
+}
+
+type indexFile struct {
+	Version int
+	Size    int64
+	ModTime time.Time
+	// Offset is the number of bytes parsed so far.
+	Offset int64
+	Events []Event
+	// SkippedLines is the number of lines that are not valid JSON.
+	SkippedLines int
+}
+
+// RebuildIndex removes the transcript index. The next parse reads every
+// transcript from scratch and creates a new index.
+func RebuildIndex() error {
+	dir, err := indexDir()
+	if err != nil {
+		return err
+	}
+	if err := os.RemoveAll(dir); err != nil {
+		return fmt.Errorf("remove index: %w", err)
+	}
+	// Older versions kept the whole index in a single file.
+	if err := os.Remove(dir + ".gob"); err != nil && !errors.Is(err, fs.ErrNotExist) {
+		return fmt.Errorf("remove index: %w", err)
+	}
+	return nil
+}
+
+func openIndex() *index {
+	dir, err := indexDir()
+	if err != nil {
+		slog.Warn("open transcript index", "err", err)
+		return &index{}
+	}
+	return &index{dir: dir}
+}
+
+// entryDir returns the dir of entries of files in the projects dir.
+func (idx *index) entryDir(projects string) string {
+	if idx.dir == "" {
+		return ""
+	}
+	hash := sha256.Sum256([]byte(projects))
+	return filepath.Join(idx.dir, hex.EncodeToString(hash[:8]))
+}
+
+// entryPath returns the path of the entry of the file at path in the
+// projects dir, or "" if the index is unavailable.
+func (idx *index) entryPath(projects, path string) string {
+	dir := idx.entryDir(projects)
+	if dir == "" {
+		return ""
+	}
+	rel, err := filepath.Rel(projects, path)
+	if err != nil || strings.HasPrefix(rel, "..") {
+		return ""
+	}
+	return filepath.Join(dir, rel+".gob")
+}
+
+// load reads the entry at path. Missing, unreadable and stale entries yield
+// an empty entry.
+func (idx *index) load(path string) *indexFile {
+	empty := &indexFile{Version: indexVersion}
+	if path == "" {
+		return empty
+	}
+	file, err := os.Open(path)
+	if err != nil {
+		return empty
+	}
+	defer func() {
+		_ = file.Close()
+	}()
+	var f indexFile
+	if err := gob.NewDecoder(file).Decode(&f); err != nil || f.Version != indexVersion {
+		return empty
+	}
+	return &f
+}
+
+// save writes the entry to path atomically.
+func (idx *index) save(path string, f *indexFile) error {
+	if path == "" {
+		return nil
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		return fmt.Errorf("create index dir: %w", err)
+	}
+	tmp, err := os.CreateTemp(filepath.Dir(path), filepath.Base(path)+".*")
+	if err != nil {
+		return fmt.Errorf("create index entry: %w", err)
+	}
+	defer func() {
+		_ = os.Remove(tmp.Name())
+	}()
+	if err := gob.NewEncoder(tmp).Encode(f); err != nil {
+		_ = tmp.Close()
+		return fmt.Errorf("encode index entry: %w", err)
+	}
+	if err := tmp.Close(); err != nil {
+		return fmt.Errorf("close index entry: %w", err)
+	}
+	return os.Rename(tmp.Name(), path)
+}
+
+// update parses the part of the file at path in the projects dir that is
+// not in the index yet, and saves the entry of the file. Files that shrank
+// or were rewritten in place are parsed from the start.
+func (idx *index) update(projects, path string, info fs.FileInfo) (*indexFile, error) {
+	entry := idx.entryPath(projects, path)
+	f := idx.load(entry)
+	if f.Size == info.Size() && f.ModTime.Equal(info.ModTime()) {
+		return f, nil
+	}
+	if info.Size() <= f.Size || info.Size() < f.Offset {
+		f = &indexFile{Version: indexVersion}
+	}
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, fmt.Errorf("open file: %w", err)
+	}
+	defer func() {
+		_ = file.Close()
+	}()
+	if _, err := file.Seek(f.Offset, io.SeekStart); err != nil {
+		return nil, fmt.Errorf("seek to offset %d: %w", f.Offset, err)
+	}
+	events, n, skipped, err := parseEvents(file)
+	if err != nil {
+		return nil, fmt.Errorf("parse events: %w", err)
+	}
+	if skipped > 0 {
+		slog.Warn("skip invalid transcript lines", "file", path, "lines", skipped)
+	}
+	updated := &indexFile{
+		Version:      indexVersion,
+		Size:         info.Size(),
+		ModTime:      info.ModTime(),
+		Offset:       f.Offset + n,
+		Events:       append(f.Events[:len(f.Events):len(f.Events)], events...),
+		SkippedLines: f.SkippedLines + skipped,
+	}
+	if err := idx.save(entry, updated); err != nil {
+		slog.Warn("save transcript index", "file", path, "err", err)
+	}
+	return updated, nil
+}
+
+// prune removes entries of files in the projects dir that were not seen.
+func (idx *index) prune(projects string, seen map[string]bool) {
+	dir := idx.entryDir(projects)
+	if dir == "" {
+		return
+	}
+	_ = filepath.WalkDir(dir, func(path string, d fs.DirEntry, err error) error {
+		if err != nil || d.IsDir() {
+			return nil
+		}
+		rel, err := filepath.Rel(dir, path)
+		if err != nil {
+			return nil
+		}
+		file, ok := strings.CutSuffix(rel, ".gob")
+		if !ok || !seen[filepath.Join(projects, file)] {
+			if err := os.Remove(path); err != nil {
+				slog.Warn("prune transcript index", "entry", path, "err", err)
+			}
+		}
+		return nil
+	})
+}
+
+// transcript returns the parsed file at path in the projects dir of the
+// root with the label.
+func (f *indexFile) transcript(projects, path, label string) (Transcript, error) {
+	rel, err := filepath.Rel(projects, path)
+	if err != nil {
+		return Transcript{}, fmt.Errorf("get relative path of %q: %w", path, err)
+	}
+	rel = filepath.ToSlash(rel)
+	project, _, _ := strings.Cut(rel, "/")
+	return Transcript{
+		File:         rel,
+		Root:         label,
+		Project:      decodeProject(project, f.Events),
+		Events:       f.Events,
+		SkippedLines: f.SkippedLines,
+		PendingBytes: max(0, f.Size-f.Offset),
+	}, nil
+}
+
+func indexDir() (string, error) {
+	dir, err := dirs.Cache()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(dir, "transcripts"), nil
+}
@@ -0,0 +1,226 @@
+package transcript_test
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+	"testing"
+
+	"github.com/iskorotkov/cc-statusline/transcript"
+)
+
+func eventLine(id string, tokens int) string {
+	return fmt.Sprintf(`{"sessionId":"s1","timestamp":"2025-01-01T10:00:00Z","message":{"id":%q,"model":"claude-sonnet-4-20250514","usage":{"input_tokens":%d}}}`+"\n", id, tokens)
+}
+
+func TestParseTranscriptsIncremental(t *testing.T) {
+	home := t.TempDir()
+	t.Setenv("HOME", home)
+	t.Setenv("XDG_CACHE_HOME", filepath.Join(home, ".cache"))
+	dir := filepath.Join(home, ".claude", "projects", "-project")
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		t.Fatal(err)
+	}
+	path := filepath.Join(dir, "s1.jsonl")
+	if err := os.WriteFile(path, []byte(eventLine("m1", 10)+eventLine("m2", 20)), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	inputTokens := func() int {
+		t.Helper()
+		transcripts, err := transcript.ParseTranscripts()
+		if err != nil {
+			t.Fatalf("ParseTranscripts() error: %v", err)
+		}
+		if len(transcripts) != 1 {
+			t.Fatalf("got %d transcripts, want 1", len(transcripts))
+		}
+		if transcripts[0].File != "-project/s1.jsonl" {
+			t.Errorf("File = %q, want %q", transcripts[0].File, "-project/s1.jsonl")
+		}
+		return transcript.SessionUsage(transcripts, "s1")["claude-sonnet-4-20250514"].InputTokens
+	}
+
+	if got := inputTokens(); got != 30 {
+		t.Errorf("input tokens = %d, want 30", got)
+	}
+
+	f, err := os.OpenFile(path, os.O_APPEND|os.O_WRONLY, 0o644)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := f.WriteString(eventLine("m3", 5)); err != nil {
+		t.Fatal(err)
+	}
+	if err := f.Close(); err != nil {
+		t.Fatal(err)
+	}
+	if got := inputTokens(); got != 35 {
+		t.Errorf("input tokens after append = %d, want 35", got)
+	}
+
+	if err := os.WriteFile(path, []byte(eventLine("m1", 1)), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if got := inputTokens(); got != 1 {
+		t.Errorf("input tokens after rewrite = %d, want 1", got)
+	}
+
+	if err := transcript.RebuildIndex(); err != nil {
+		t.Fatalf("RebuildIndex() error: %v", err)
+	}
+	if got := inputTokens(); got != 1 {
+		t.Errorf("input tokens after rebuild = %d, want 1", got)
+	}
+}
+
+func TestParseTranscriptsIndexEntries(t *testing.T) {
+	home := t.TempDir()
+	t.Setenv("HOME", home)
+	cache := filepath.Join(home, ".cache")
+	t.Setenv("XDG_CACHE_HOME", cache)
+	dir := filepath.Join(home, ".claude", "projects", "-project")
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		t.Fatal(err)
+	}
+	for _, name := range []string{"s1.jsonl", "s2.jsonl"} {
+		if err := os.WriteFile(filepath.Join(dir, name), []byte(eventLine(name, 10)), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	entries := func() []string {
+		t.Helper()
+		matches, err := filepath.Glob(filepath.Join(cache, "cc-statusline", "transcripts", "*", "-project", "*.gob"))
+		if err != nil {
+			t.Fatal(err)
+		}
+		for i, m := range matches {
+			matches[i] = filepath.Base(m)
+		}
+		return matches
+	}
+
+	if _, err := transcript.ParseTranscripts(); err != nil {
+		t.Fatalf("ParseTranscripts() error: %v", err)
+	}
+	if got, want := entries(), []string{"s1.jsonl.gob", "s2.jsonl.gob"}; !slices.Equal(got, want) {
+		t.Errorf("index entries = %v, want %v", got, want)
+	}
+
+	if err := os.Remove(filepath.Join(dir, "s2.jsonl")); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := transcript.ParseTranscripts(); err != nil {
+		t.Fatalf("ParseTranscripts() error: %v", err)
+	}
+	if got, want := entries(), []string{"s1.jsonl.gob"}; !slices.Equal(got, want) {
+		t.Errorf("index entries after remove = %v, want %v", got, want)
+	}
+}
+
+func TestParseTranscriptsCorrupt(t *testing.T) {
+	home := t.TempDir()
+	t.Setenv("HOME", home)
+	t.Setenv("XDG_CACHE_HOME", filepath.Join(home, ".cache"))
+	dir := filepath.Join(home, ".claude", "projects", "-project")
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		t.Fatal(err)
+	}
+	path := filepath.Join(dir, "s1.jsonl")
+	partial := eventLine("m4", 100)
+	partial = partial[:len(partial)/2]
+	content := eventLine("m1", 10) + "{not json\n" + eventLine("m2", 20) + "\n" + `{"truncated":` + "\n" + eventLine("m3", 30) + partial
+	if err := os.WriteFile(path, []byte(content), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	transcripts, err := transcript.ParseTranscripts()
+	if err != nil {
+		t.Fatalf("ParseTranscripts() error: %v", err)
+	}
+	if len(transcripts) != 1 {
+		t.Fatalf("got %d transcripts, want 1", len(transcripts))
+	}
+	if got := len(transcripts[0].Events); got != 3 {
+		t.Errorf("got %d events, want 3", got)
+	}
+	d := transcript.Diagnose(transcripts)
+	if got := d.Skipped(); got != 2 {
+		t.Errorf("Skipped() = %d, want 2", got)
+	}
+	if got := d.PendingBytes["-project/s1.jsonl"]; got != int64(len(partial)) {
+		t.Errorf("pending bytes = %d, want %d", got, len(partial))
+	}
+
+	f, err := os.OpenFile(path, os.O_APPEND|os.O_WRONLY, 0o644)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := f.WriteString(eventLine("m4", 100)[len(partial):]); err != nil {
+		t.Fatal(err)
+	}
+	if err := f.Close(); err != nil {
+		t.Fatal(err)
+	}
+	transcripts, err = transcript.ParseTranscripts()
+	if err != nil {
+		t.Fatalf("ParseTranscripts() error: %v", err)
+	}
+	if got := transcript.SessionUsage(transcripts, "s1")["claude-sonnet-4-20250514"].InputTokens; got != 160 {
+		t.Errorf("input tokens = %d, want 160", got)
+	}
+	d = transcript.Diagnose(transcripts)
+	if got := d.Skipped(); got != 2 {
+		t.Errorf("Skipped() after append = %d, want 2", got)
+	}
+	if len(d.PendingBytes) != 0 {
+		t.Errorf("pending bytes after append = %v, want none", d.PendingBytes)
+	}
+}
+
+func TestParseRoots(t *testing.T) {
+	home := t.TempDir()
+	t.Setenv("HOME", home)
+	t.Setenv("XDG_CACHE_HOME", filepath.Join(home, ".cache"))
+	t.Setenv("CLAUDE_CONFIG_DIR", filepath.Join(home, "personal"))
+	for dir, tokens := range map[string]int{"personal": 10, "work": 20} {
+		project := filepath.Join(home, dir, "projects", "-project")
+		if err := os.MkdirAll(project, 0o755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(filepath.Join(project, "s1.jsonl"), []byte(eventLine(dir, tokens)), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	roots, err := transcript.DefaultRoots()
+	if err != nil {
+		t.Fatalf("DefaultRoots() error: %v", err)
+	}
+	roots = append(roots,
+		transcript.Root{Label: "work", Dir: "~/work"},
+		transcript.Root{Label: "missing", Dir: filepath.Join(home, "missing")},
+	)
+	transcripts, err := transcript.ParseRoots(roots)
+	if err != nil {
+		t.Fatalf("ParseRoots() error: %v", err)
+	}
+	if len(transcripts) != 2 {
+		t.Fatalf("got %d transcripts, want 2", len(transcripts))
+	}
+	for label, want := range map[string]int{transcript.DefaultRoot: 10, "work": 20} {
+		filtered := transcript.FilterRoot(transcripts, label)
+		if len(filtered) != 1 {
+			t.Fatalf("got %d transcripts in root %q, want 1", len(filtered), label)
+		}
+		if got := transcript.SessionUsage(filtered, "s1")["claude-sonnet-4-20250514"].InputTokens; got != want {
+			t.Errorf("input tokens in root %q = %d, want %d", label, got, want)
+		}
+	}
+
+	if _, err := transcript.ParseRoots([]transcript.Root{{Label: "missing", Dir: filepath.Join(home, "missing")}}); err == nil {
+		t.Error("ParseRoots() with no readable root succeeded, want error")
+	}
+}
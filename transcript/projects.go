@@ -0,0 +1,103 @@
+package transcript
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+)
+
+// EncodeProject returns the name of the dir Claude Code stores transcripts
+// of the project dir in. Every character other than an ASCII letter or digit
+// is replaced with "-".
+func EncodeProject(dir string) string {
+	return strings.Map(func(r rune) rune {
+		if r >= 'a' && r <= 'z' || r >= 'A' && r <= 'Z' || r >= '0' && r <= '9' {
+			return r
+		}
+		return '-'
+	}, dir)
+}
+
+// FilterProject returns transcripts of the project dir.
+func FilterProject(transcripts []Transcript, dir string) []Transcript {
+	name := EncodeProject(dir)
+	var filtered []Transcript
+	for _, t := range transcripts {
+		if EncodeProject(t.Project) == name {
+			filtered = append(filtered, t)
+		}
+	}
+	return filtered
+}
+
+// FilterProjectTree returns transcripts of the project dir and of projects
+// in its subdirs, such as sessions started in a subdir of a repo.
+func FilterProjectTree(transcripts []Transcript, dir string) []Transcript {
+	dir = filepath.Clean(dir)
+	name := EncodeProject(dir)
+	var filtered []Transcript
+	for _, t := range transcripts {
+		var ok bool
+		if filepath.IsAbs(t.Project) {
+			ok = t.Project == dir || strings.HasPrefix(t.Project, dir+string(filepath.Separator))
+		} else {
+			// The project dir could not be decoded, so compare encoded names.
+			project := EncodeProject(t.Project)
+			ok = project == name || strings.HasPrefix(project, name+"-")
+		}
+		if ok {
+			filtered = append(filtered, t)
+		}
+	}
+	return filtered
+}
+
+var decodedProjects sync.Map
+
+// decodeProject returns the project dir encoded in the name of a dir with
+// transcripts. Encoding is lossy, so the working dir of an event that
+// encodes to the name is used if there is one. Otherwise the path is looked
+// up on disk, and the name itself is returned if nothing matches.
+func decodeProject(name string, events []Event) string {
+	for _, e := range events {
+		if e.Cwd != "" && EncodeProject(e.Cwd) == name {
+			return e.Cwd
+		}
+	}
+	if dir, ok := decodedProjects.Load(name); ok {
+		return dir.(string)
+	}
+	dir := name
+	if strings.HasPrefix(name, "-") {
+		if found, ok := findProject(string(filepath.Separator), name[1:]); ok {
+			dir = found
+		}
+	}
+	decodedProjects.Store(name, dir)
+	return dir
+}
+
+// findProject finds a path under dir whose remainder encodes to rest.
+func findProject(dir, rest string) (string, bool) {
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return "", false
+	}
+	for _, e := range entries {
+		if !e.IsDir() {
+			continue
+		}
+		name := EncodeProject(e.Name())
+		path := filepath.Join(dir, e.Name())
+		if name == rest {
+			return path, true
+		}
+		if strings.HasPrefix(rest, name+"-") {
+			if found, ok := findProject(path, rest[len(name)+1:]); ok {
+				return found, true
+			}
+		}
+	}
+	return "", false
+}
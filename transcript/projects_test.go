@@ -0,0 +1,79 @@
+package transcript_test
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/iskorotkov/cc-statusline/transcript"
+)
+
+func TestEncodeProject(t *testing.T) {
+	if got := transcript.EncodeProject("/home/me/src/my_app.v2"); got != "-home-me-src-my-app-v2" {
+		t.Errorf("EncodeProject() = %q, want %q", got, "-home-me-src-my-app-v2")
+	}
+}
+
+func TestParseRootsProjects(t *testing.T) {
+	home := t.TempDir()
+	t.Setenv("HOME", home)
+	t.Setenv("XDG_CACHE_HOME", filepath.Join(home, ".cache"))
+	withCwd := filepath.Join(home, "src", "with-cwd")
+	onDisk := filepath.Join(home, "src", "my_app.v2")
+	if err := os.MkdirAll(onDisk, 0o755); err != nil {
+		t.Fatal(err)
+	}
+	line := func(cwd string) string {
+		return fmt.Sprintf(`{"sessionId":"s1","cwd":%q,"timestamp":"2025-01-01T10:00:00Z","message":{"id":"m1","model":"claude-sonnet-4-20250514","usage":{"input_tokens":1}}}`+"\n", cwd)
+	}
+	files := map[string]string{
+		transcript.EncodeProject(withCwd): line(filepath.Join(withCwd, "sub")) + line(withCwd),
+		transcript.EncodeProject(onDisk):  line(""),
+		"-missing-project":                line(""),
+	}
+	for dir, content := range files {
+		path := filepath.Join(home, ".claude", "projects", dir, "s1.jsonl")
+		if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, []byte(content), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	transcripts, err := transcript.ParseTranscripts()
+	if err != nil {
+		t.Fatalf("ParseTranscripts() error: %v", err)
+	}
+	projects := make(map[string]bool)
+	for _, tr := range transcripts {
+		projects[tr.Project] = true
+	}
+	for _, want := range []string{withCwd, onDisk, "-missing-project"} {
+		if !projects[want] {
+			t.Errorf("projects = %v, want %q", projects, want)
+		}
+	}
+	if got := transcript.FilterProject(transcripts, onDisk); len(got) != 1 || got[0].Project != onDisk {
+		t.Errorf("FilterProject() = %v, want the transcript of %q", got, onDisk)
+	}
+}
+
+func TestFilterProjectTree(t *testing.T) {
+	transcripts := []transcript.Transcript{
+		{File: "a", Project: "/src/app"},
+		{File: "b", Project: "/src/app/web"},
+		{File: "c", Project: "/src/app-v2"},
+		{File: "d", Project: "-src-app-cli"},
+		{File: "e", Project: "/src/other"},
+	}
+	var got []string
+	for _, tr := range transcript.FilterProjectTree(transcripts, "/src/app/") {
+		got = append(got, tr.File)
+	}
+	if strings.Join(got, " ") != "a b d" {
+		t.Errorf("FilterProjectTree() = %v, want [a b d]", got)
+	}
+}
@@ -0,0 +1,53 @@
+package transcript
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// DefaultRoot is the label of the Claude config dir used by Claude Code.
+const DefaultRoot = "default"
+
+// Root is a Claude config dir with transcripts in its projects dir.
+type Root struct {
+	Label string
+	Dir   string
+}
+
+// DefaultRoots returns the Claude config dir from CLAUDE_CONFIG_DIR, or
+// ~/.claude if it is not set.
+func DefaultRoots() ([]Root, error) {
+	if dir := os.Getenv("CLAUDE_CONFIG_DIR"); dir != "" {
+		return []Root{{Label: DefaultRoot, Dir: dir}}, nil
+	}
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return nil, fmt.Errorf("get user home dir: %w", err)
+	}
+	return []Root{{Label: DefaultRoot, Dir: filepath.Join(home, ".claude")}}, nil
+}
+
+// FilterRoot returns transcripts belonging to the root with the label.
+func FilterRoot(transcripts []Transcript, label string) []Transcript {
+	var filtered []Transcript
+	for _, t := range transcripts {
+		if t.Root == label {
+			filtered = append(filtered, t)
+		}
+	}
+	return filtered
+}
+
+// expandHome replaces a leading ~ in path with the user home dir.
+func expandHome(path string) (string, error) {
+	if path != "~" && !strings.HasPrefix(path, "~/") {
+		return path, nil
+	}
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return "", fmt.Errorf("get user home dir: %w", err)
+	}
+	return filepath.Join(home, strings.TrimPrefix(path, "~")), nil
+}
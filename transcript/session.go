@@ -0,0 +1,95 @@
+package transcript
+
+import (
+	"errors"
+	"fmt"
+	"io/fs"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"slices"
+)
+
+// ParseSession parses the transcript at path, which belongs to the session,
+// and other transcripts in its project dir with events of the session, such
+// as continuations of a resumed session and subagent transcripts. Only the
+// project dir and its index entries are read, which is much cheaper than
+// parsing all transcripts.
+// Transcripts are labeled with the root whose projects dir contains path.
+func ParseSession(roots []Root, path, sessionID string) ([]Transcript, error) {
+	path, err := expandHome(path)
+	if err != nil {
+		return nil, err
+	}
+	path, err = filepath.Abs(path)
+	if err != nil {
+		return nil, fmt.Errorf("get absolute path of %q: %w", path, err)
+	}
+	projectDir := filepath.Dir(path)
+	projects := filepath.Dir(projectDir)
+	var label string
+	for _, r := range roots {
+		dir, err := expandHome(r.Dir)
+		if err != nil {
+			return nil, err
+		}
+		if filepath.Join(filepath.Clean(dir), "projects") == projects {
+			label = r.Label
+			break
+		}
+	}
+
+	idx := openIndex()
+	var transcripts []Transcript
+	// Claude Code creates the transcript of a new session with its first
+	// message, so a missing transcript has no events yet.
+	info, err := os.Stat(path)
+	switch {
+	case errors.Is(err, fs.ErrNotExist):
+	case err != nil:
+		return nil, fmt.Errorf("stat transcript %q: %w", path, err)
+	default:
+		f, err := idx.update(projects, path, info)
+		if err != nil {
+			return nil, fmt.Errorf("parse transcript %q: %w", path, err)
+		}
+		t, err := f.transcript(projects, path, label)
+		if err != nil {
+			return nil, err
+		}
+		transcripts = append(transcripts, t)
+	}
+	if err := filepath.WalkDir(projectDir, func(p string, d fs.DirEntry, err error) error {
+		if err != nil {
+			if !errors.Is(err, fs.ErrNotExist) {
+				slog.Warn("access transcript path", "path", p, "err", err)
+			}
+			return nil
+		}
+		if d.IsDir() || filepath.Ext(p) != ".jsonl" || p == path {
+			return nil
+		}
+		info, err := d.Info()
+		if err != nil {
+			slog.Warn("stat transcript", "file", p, "err", err)
+			return nil
+		}
+		f, err := idx.update(projects, p, info)
+		if err != nil {
+			slog.Warn("parse transcript", "file", p, "err", err)
+			return nil
+		}
+		if !slices.ContainsFunc(f.Events, func(e Event) bool { return e.SessionID == sessionID }) {
+			return nil
+		}
+		t, err := f.transcript(projects, p, label)
+		if err != nil {
+			return err
+		}
+		transcripts = append(transcripts, t)
+		return nil
+	}); err != nil {
+		return nil, fmt.Errorf("walk dir %q: %w", projectDir, err)
+	}
+	return transcripts, nil
+}
@@ -0,0 +1,84 @@
+package transcript_test
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+	"testing"
+
+	"github.com/iskorotkov/cc-statusline/transcript"
+)
+
+func TestParseSession(t *testing.T) {
+	home := t.TempDir()
+	t.Setenv("HOME", home)
+	t.Setenv("XDG_CACHE_HOME", filepath.Join(home, ".cache"))
+	line := func(session, id string) string {
+		return fmt.Sprintf(`{"sessionId":%q,"timestamp":"2025-01-01T10:00:00Z","message":{"id":%q,"model":"claude-sonnet-4-20250514","usage":{"input_tokens":1}}}`+"\n", session, id)
+	}
+	projects := filepath.Join(home, ".claude", "projects")
+	files := map[string]string{
+		"-project/s2.jsonl":                line("s1", "m1") + line("s2", "m2"),
+		"-project/s1.jsonl":                line("s2", "m3"),
+		"-project/s3.jsonl":                line("s3", "m4"),
+		"-project/s2/subagents/a1.jsonl":   line("s2", "m5"),
+		"-other/s2.jsonl":                  line("s2", "m6"),
+		"-project/s2/subagents/notes.json": line("s2", "m7"),
+	}
+	for name, content := range files {
+		path := filepath.Join(projects, filepath.FromSlash(name))
+		if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, []byte(content), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	roots, err := transcript.DefaultRoots()
+	if err != nil {
+		t.Fatalf("DefaultRoots() error: %v", err)
+	}
+	transcripts, err := transcript.ParseSession(roots, filepath.Join(projects, "-project", "s2.jsonl"), "s2")
+	if err != nil {
+		t.Fatalf("ParseSession() error: %v", err)
+	}
+	var got []string
+	for _, tr := range transcripts {
+		if tr.Root != transcript.DefaultRoot {
+			t.Errorf("transcript %q root = %q, want %q", tr.File, tr.Root, transcript.DefaultRoot)
+		}
+		got = append(got, tr.File)
+	}
+	slices.Sort(got)
+	want := []string{"-project/s1.jsonl", "-project/s2.jsonl", "-project/s2/subagents/a1.jsonl"}
+	if !slices.Equal(got, want) {
+		t.Errorf("files = %v, want %v", got, want)
+	}
+	if got := transcript.SessionUsage(transcripts, "s2")["claude-sonnet-4-20250514"].InputTokens; got != 3 {
+		t.Errorf("session input tokens = %d, want 3", got)
+	}
+	index := filepath.Join(home, ".cache", "cc-statusline", "transcripts", "*")
+	if entries, _ := filepath.Glob(filepath.Join(index, "-project", "*.gob")); len(entries) != 3 {
+		t.Errorf("got %d index entries in the project dir, want 3", len(entries))
+	}
+	if entries, _ := filepath.Glob(filepath.Join(index, "-other", "*")); len(entries) != 0 {
+		t.Errorf("index entries of other projects = %v, want none", entries)
+	}
+
+	// The transcript of a new session is created with its first message.
+	missing := filepath.Join(projects, "-project", "missing.jsonl")
+	transcripts, err = transcript.ParseSession(roots, missing, "s4")
+	if err != nil || len(transcripts) != 0 {
+		t.Errorf("ParseSession() with missing transcript = %d transcripts, %v, want none", len(transcripts), err)
+	}
+	transcripts, err = transcript.ParseSession(roots, missing, "s2")
+	if err != nil || len(transcripts) != 3 {
+		t.Errorf("ParseSession() with missing transcript of a resumed session = %d transcripts, %v, want 3", len(transcripts), err)
+	}
+	transcripts, err = transcript.ParseSession(roots, filepath.Join(projects, "-new", "s5.jsonl"), "s5")
+	if err != nil || len(transcripts) != 0 {
+		t.Errorf("ParseSession() in missing project dir = %d transcripts, %v, want none", len(transcripts), err)
+	}
+}
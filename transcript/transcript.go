@@ -2,23 +2,27 @@ package transcript
 
 import (
 	"bufio"
+	"bytes"
 	"encoding/json"
 	"errors"
 	"fmt"
 	"io"
 	"io/fs"
-	"os"
+	"log/slog"
 	"path/filepath"
 	"time"
 )
 
 type Event struct {
 	SessionID string       `json:"sessionId"`
+	RequestID string       `json:"requestId"`
 	Timestamp time.Time    `json:"timestamp"`
 	Type      string       `json:"type"`
 	Cwd       string       `json:"cwd"`
 	GitBranch string       `json:"gitBranch"`
 	Message   EventMessage `json:"message"`
+	// IsSidechain is set for events of subagents.
+	IsSidechain bool `json:"isSidechain"`
 }
 
 type EventMessage struct {
@@ -34,73 +38,160 @@ type EventUsage struct {
 	CacheCreationInputTokens int `json:"cache_creation_input_tokens"`
 	CacheReadInputTokens     int `json:"cache_read_input_tokens"`
 	OutputTokens             int `json:"output_tokens"`
+	// CacheCreation splits cache writes by cache lifetime. Older transcripts
+	// do not have it.
+	CacheCreation CacheCreation `json:"cache_creation"`
+	// ServerToolUse counts server tool requests, which are billed per
+	// request on top of tokens.
+	ServerToolUse ServerToolUse `json:"server_tool_use"`
+}
+
+type ServerToolUse struct {
+	WebSearchRequests int `json:"web_search_requests"`
+	WebFetchRequests  int `json:"web_fetch_requests"`
+}
+
+type CacheCreation struct {
+	Ephemeral5mInputTokens int `json:"ephemeral_5m_input_tokens"`
+	Ephemeral1hInputTokens int `json:"ephemeral_1h_input_tokens"`
+}
+
+// Input returns the number of input tokens of the request, cached or not.
+func (u EventUsage) Input() int {
+	return u.InputTokens + u.CacheCreationInputTokens + u.CacheReadInputTokens
 }
 
 type Transcript struct {
-	File   string
-	Events []Event
+	// File is the path of the transcript relative to the projects dir.
+	File string
+	// Root is the label of the Claude config dir the transcript belongs to.
+	Root string
+	// Project is the project dir of the transcript, decoded from the name of
+	// the dir in the projects dir.
+	Project string
+	Events  []Event
+	// SkippedLines is the number of lines that are not valid JSON.
+	SkippedLines int
+	// PendingBytes is the size of the unterminated last line, which is
+	// parsed once Claude Code finishes writing it.
+	PendingBytes int64
 }
 
+// ParseTranscripts parses transcripts from the default Claude config dir.
 func ParseTranscripts() ([]Transcript, error) {
-	path, err := transcriptPath()
+	roots, err := DefaultRoots()
 	if err != nil {
-		return nil, fmt.Errorf("get transcript path: %w", err)
+		return nil, fmt.Errorf("get transcript roots: %w", err)
+	}
+	return ParseRoots(roots)
+}
+
+// ParseRoots parses transcripts from the projects dirs of all roots. Files
+// are parsed incrementally: only lines appended since the previous run are
+// read, the rest comes from the index in the cache dir. The index entry of
+// each file is saved as soon as the file is parsed, so parsing that does not
+// finish in time still speeds up the next run. Roots and files that
+// cannot be read are skipped and reported to the debug log, and an error is
+// returned only if no root could be read.
+func ParseRoots(roots []Root) ([]Transcript, error) {
+	idx := openIndex()
+	visited := make(map[string]bool)
+	var transcripts []Transcript
+	var errs []error
+	for _, root := range roots {
+		dir, err := expandHome(root.Dir)
+		if err != nil {
+			return nil, err
+		}
+		root.Dir = filepath.Clean(dir)
+		if visited[root.Dir] {
+			continue
+		}
+		visited[root.Dir] = true
+		parsed, err := parseRoot(idx, root)
+		if err != nil {
+			slog.Warn("parse transcript root", "root", root.Label, "dir", root.Dir, "err", err)
+			errs = append(errs, err)
+			continue
+		}
+		transcripts = append(transcripts, parsed...)
 	}
-	dir := os.DirFS(path)
+	if len(errs) > 0 && len(errs) == len(visited) {
+		return nil, errors.Join(errs...)
+	}
+	return transcripts, nil
+}
+
+// parseRoot parses transcripts from the projects dir of the root, and removes
+// index entries of files that no longer exist once the whole dir is walked.
+func parseRoot(idx *index, r Root) ([]Transcript, error) {
+	root := filepath.Join(r.Dir, "projects")
+	seen := make(map[string]bool)
 	var transcripts []Transcript
-	if err := fs.WalkDir(dir, ".", func(path string, d fs.DirEntry, err error) error {
+	if err := filepath.WalkDir(root, func(path string, d fs.DirEntry, err error) error {
 		if err != nil {
-			return fmt.Errorf("access path %q: %w", path, err)
+			if path == root {
+				return fmt.Errorf("access path %q: %w", path, err)
+			}
+			slog.Warn("access transcript path", "path", path, "err", err)
+			return nil
 		}
 		if d.IsDir() || filepath.Ext(path) != ".jsonl" {
 			return nil
 		}
-		f, err := dir.Open(path)
+		info, err := d.Info()
 		if err != nil {
-			return fmt.Errorf("open file %q: %w", path, err)
+			slog.Warn("stat transcript", "file", path, "err", err)
+			return nil
 		}
-		defer func() {
-			_ = f.Close()
-		}()
-		events, err := parseEvents(f)
+		f, err := idx.update(root, path, info)
 		if err != nil {
-			return fmt.Errorf("parse events in file %q: %w", path, err)
+			slog.Warn("parse transcript", "file", path, "err", err)
+			return nil
 		}
-		if len(events) > 0 {
-			transcripts = append(transcripts, Transcript{
-				File:   path,
-				Events: events,
-			})
+		seen[path] = true
+		if len(f.Events) > 0 || f.SkippedLines > 0 {
+			t, err := f.transcript(root, path, r.Label)
+			if err != nil {
+				return err
+			}
+			transcripts = append(transcripts, t)
 		}
 		return nil
 	}); err != nil {
-		return nil, fmt.Errorf("walk dir %q: %w", dir, err)
+		return nil, fmt.Errorf("walk dir %q: %w", root, err)
 	}
+	idx.prune(root, seen)
 	return transcripts, nil
 }
 
-func parseEvents(f io.Reader) ([]Event, error) {
-	dec := json.NewDecoder(bufio.NewReader(f))
-	var events []Event
+// parseEvents decodes events with usage from complete lines of f. Lines that
+// are not valid JSON are skipped and counted. An unterminated last line is
+// left for later, since Claude Code may still be writing it. The returned
+// offset is where parsing continues once more data is appended.
+func parseEvents(f io.Reader) (events []Event, offset int64, skipped int, err error) {
+	r := bufio.NewReader(f)
 	for {
-		var e Event
-		if err := dec.Decode(&e); errors.Is(err, io.EOF) {
+		line, err := r.ReadBytes('\n')
+		if errors.Is(err, io.EOF) {
 			break
 		} else if err != nil {
-			return nil, fmt.Errorf("decode JSON: %w", err)
+			return nil, 0, 0, fmt.Errorf("read line: %w", err)
+		}
+		offset += int64(len(line))
+		line = bytes.TrimSpace(line)
+		if len(line) == 0 {
+			continue
+		}
+		var e Event
+		if err := json.Unmarshal(line, &e); err != nil {
+			skipped++
+			continue
 		}
 		if e.Message.Usage == (EventUsage{}) {
 			continue
 		}
 		events = append(events, e)
 	}
-	return events, nil
-}
-
-func transcriptPath() (string, error) {
-	home, err := os.UserHomeDir()
-	if err != nil {
-		return "", fmt.Errorf("get user home dir: %w", err)
-	}
-	return filepath.Join(home, ".claude", "projects"), nil
+	return events, offset, skipped, nil
 }
@@ -0,0 +1,32 @@
+package transcript
+
+import "time"
+
+// Calendar windows are computed in the location of the given time from
+// calendar dates, so days spanning a DST change are not 24 hours long.
+
+// Hour returns the start and end of the hour containing t.
+func Hour(t time.Time) (from, to time.Time) {
+	from = time.Date(t.Year(), t.Month(), t.Day(), t.Hour(), 0, 0, 0, t.Location())
+	return from, from.Add(time.Hour)
+}
+
+// Day returns the start and end of the day containing t.
+func Day(t time.Time) (from, to time.Time) {
+	from = time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, t.Location())
+	return from, from.AddDate(0, 0, 1)
+}
+
+// Week returns the start and end of the week containing t, where weeks
+// begin on the start day.
+func Week(t time.Time, start time.Weekday) (from, to time.Time) {
+	day, _ := Day(t)
+	from = day.AddDate(0, 0, -((int(t.Weekday()) - int(start) + 7) % 7))
+	return from, from.AddDate(0, 0, 7)
+}
+
+// Month returns the start and end of the month containing t.
+func Month(t time.Time) (from, to time.Time) {
+	from = time.Date(t.Year(), t.Month(), 1, 0, 0, 0, 0, t.Location())
+	return from, from.AddDate(0, 1, 0)
+}
@@ -0,0 +1,53 @@
+package transcript_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/iskorotkov/cc-statusline/transcript"
+)
+
+func TestWindows(t *testing.T) {
+	msk := time.FixedZone("UTC+3", 3*60*60)
+	// Wednesday 02:30 in UTC+3 is still Tuesday in UTC.
+	now := time.Date(2025, 1, 1, 2, 30, 0, 0, msk)
+	day := func(d int) time.Time {
+		return time.Date(2025, 1, d, 0, 0, 0, 0, msk)
+	}
+	week := func(start time.Weekday) func(time.Time) (time.Time, time.Time) {
+		return func(t time.Time) (time.Time, time.Time) {
+			return transcript.Week(t, start)
+		}
+	}
+	tests := []struct {
+		name     string
+		window   func(time.Time) (time.Time, time.Time)
+		wantFrom time.Time
+		wantTo   time.Time
+	}{
+		{"hour", transcript.Hour, now.Add(-30 * time.Minute), now.Add(30 * time.Minute)},
+		{"day", transcript.Day, day(1), day(2)},
+		{"week from monday", week(time.Monday), day(1).AddDate(0, 0, -2), day(1).AddDate(0, 0, 5)},
+		{"week from sunday", week(time.Sunday), day(1).AddDate(0, 0, -3), day(1).AddDate(0, 0, 4)},
+		{"week from wednesday", week(time.Wednesday), day(1), day(8)},
+		{"month", transcript.Month, day(1), time.Date(2025, 2, 1, 0, 0, 0, 0, msk)},
+	}
+	for _, tt := range tests {
+		from, to := tt.window(now)
+		if !from.Equal(tt.wantFrom) || !to.Equal(tt.wantTo) {
+			t.Errorf("%s = [%v, %v), want [%v, %v)", tt.name, from, to, tt.wantFrom, tt.wantTo)
+		}
+	}
+}
+
+func TestUsageByDateLocation(t *testing.T) {
+	msk := time.FixedZone("UTC+3", 3*60*60)
+	e := transcript.Event{Timestamp: time.Date(2024, 12, 31, 23, 30, 0, 0, time.UTC)}
+	e.Message.Model = "claude-sonnet-4-20250514"
+	e.Message.Usage.InputTokens = 1
+	usages := transcript.UsageByDate([]transcript.Transcript{{Events: []transcript.Event{e}}}, msk)
+	key := transcript.TimeModel{Date: time.Date(2025, 1, 1, 0, 0, 0, 0, msk), Model: e.Message.Model}
+	if usages[key].InputTokens != 1 {
+		t.Errorf("usage by date = %v, want usage on %v", usages, key.Date)
+	}
+}